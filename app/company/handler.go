@@ -104,3 +104,38 @@ func (hdl *Handler) GetCompany() http.HandlerFunc {
 		}
 	}
 }
+
+func (hdl *Handler) UpdateTwoFactorPolicy() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.TwoFactorPolicyRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.UpdateTwoFactorPolicy(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package company
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+	"go-edash/enums"
+	"time"
+)
+
+// InvitationRepository stores the invitations of the company the context is scoped to.
+type InvitationRepository struct {
+}
+
+// invitationColumns lists the columns read by scanInvitation, in the order they are scanned.
+const invitationColumns = "id, company_id, email, role, invited_by, status, expires_at"
+
+func scanInvitation(rows *sql.Rows) *domain.Invitation {
+	invitation := new(domain.Invitation)
+
+	err := rows.Scan(&invitation.Id, &invitation.CompanyId, &invitation.Email, &invitation.Role,
+		&invitation.InvitedBy, &invitation.Status, &invitation.ExpiresAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return invitation
+}
+
+func (rpo *InvitationRepository) Create(ctx context.Context, tx *sql.Tx, invitation *domain.Invitation) *domain.Invitation {
+	invitation.CompanyId = domain.MustTenant(ctx)
+
+	query := `insert into invitations (id,company_id,email,role,invited_by,status,expires_at)
+	values (?,?,?,?,?,?,?)`
+
+	_, err := tx.ExecContext(ctx, query, invitation.Id, invitation.CompanyId, invitation.Email, invitation.Role,
+		invitation.InvitedBy, invitation.Status, invitation.ExpiresAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return invitation
+}
+
+// UpdateStatus records the answer to an invitation, or its revocation.
+func (rpo *InvitationRepository) UpdateStatus(ctx context.Context, tx *sql.Tx, invitation *domain.Invitation) *domain.Invitation {
+	query := "update invitations set status=?,responded_at=now() where id = ? and company_id = ?"
+
+	result, err := tx.ExecContext(ctx, query, invitation.Status, invitation.Id, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	domain.MustAffectTenant(result, "invitation")
+
+	return invitation
+}
+
+// FindById returns the invitation and locks it until the transaction ends, so that it is only answered once.
+func (rpo *InvitationRepository) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.Invitation, error) {
+	query := "select " + invitationColumns + " from invitations where id = ? and company_id = ? for update"
+
+	rows, err := tx.QueryContext(ctx, query, id, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanInvitation(rows), nil
+	} else {
+		return new(domain.Invitation), errors.New("invitation not found")
+	}
+}
+
+// FindPending returns the invitations of the company that can still be accepted at the given time.
+func (rpo *InvitationRepository) FindPending(ctx context.Context, tx *sql.Tx, at time.Time) []*domain.Invitation {
+	query := "select " + invitationColumns + ` from invitations
+	where company_id = ? and status = ? and expires_at > ? order by expires_at`
+
+	rows, err := tx.QueryContext(ctx, query, domain.MustTenant(ctx), enums.INVITATION_PENDING, at)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var invitations []*domain.Invitation
+	for rows.Next() {
+		invitations = append(invitations, scanInvitation(rows))
+	}
+
+	return invitations
+}
+
+// RevokePendingByEmail revokes the pending invitations sent to the email by the company.
+func (rpo *InvitationRepository) RevokePendingByEmail(ctx context.Context, tx *sql.Tx, email string) {
+	query := "update invitations set status=?,responded_at=now() where company_id = ? and email = ? and status = ?"
+
+	_, err := tx.ExecContext(ctx, query, enums.INVITATION_REVOKED, domain.MustTenant(ctx), email,
+		enums.INVITATION_PENDING)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RevokePending revokes every pending invitation of the company.
+func (rpo *InvitationRepository) RevokePending(ctx context.Context, tx *sql.Tx) {
+	query := "update invitations set status=?,responded_at=now() where company_id = ? and status = ?"
+
+	_, err := tx.ExecContext(ctx, query, enums.INVITATION_REVOKED, domain.MustTenant(ctx), enums.INVITATION_PENDING)
+	if err != nil {
+		panic(err)
+	}
+}
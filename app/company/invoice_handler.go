@@ -0,0 +1,56 @@
+package company
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"go-edash/domain"
+	"go-edash/response"
+	"net/http"
+	"strconv"
+)
+
+type InvoiceHandler struct {
+	svc domain.InvoiceService
+}
+
+// GetInvoices is an HTTP handler function that returns the billing history of the company of the authenticated user.
+// The response status code is set to 200 OK.
+func (hdl *InvoiceHandler) GetInvoices() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.GetInvoices(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// DownloadInvoice is an HTTP handler function that returns the invoice named by the id URL parameter as a PDF file.
+// The response status code is set to 200 OK.
+func (hdl *InvoiceHandler) DownloadInvoice() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.DownloadInvoice(ctx, chi.URLParam(request, "id"))
+
+		writer.Header().Set("Content-Type", "application/pdf")
+		writer.Header().Set("Content-Disposition", "attachment; filename=\""+result.FileName+"\"")
+		writer.Header().Set("Content-Length", strconv.Itoa(len(result.Content)))
+
+		_, err := writer.Write(result.Content)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
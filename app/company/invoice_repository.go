@@ -0,0 +1,108 @@
+package company
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+// InvoiceRepository stores the invoices of the company the context is scoped to.
+// The invoice numbers are sequential across every company.
+type InvoiceRepository struct {
+}
+
+// invoiceColumns lists the columns read by scanInvoice, in the order they are scanned.
+const invoiceColumns = "id, number, company_id, payment_id, plan_name, amount, period_start, period_end, issued_at"
+
+func scanInvoice(rows *sql.Rows) *domain.Invoice {
+	invoice := new(domain.Invoice)
+
+	err := rows.Scan(&invoice.Id, &invoice.Number, &invoice.CompanyId, &invoice.PaymentId, &invoice.PlanName,
+		&invoice.Amount, &invoice.PeriodStart, &invoice.PeriodEnd, &invoice.IssuedAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return invoice
+}
+
+func (rpo *InvoiceRepository) Create(ctx context.Context, tx *sql.Tx, invoice *domain.Invoice) *domain.Invoice {
+	invoice.CompanyId = domain.MustTenant(ctx)
+
+	query := `insert into invoices (id,number,company_id,payment_id,plan_name,amount,period_start,period_end,issued_at)
+	values (?,?,?,?,?,?,?,?,?)`
+
+	_, err := tx.ExecContext(ctx, query, invoice.Id, invoice.Number, invoice.CompanyId, invoice.PaymentId,
+		invoice.PlanName, invoice.Amount, invoice.PeriodStart, invoice.PeriodEnd, invoice.IssuedAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return invoice
+}
+
+// NextSequence returns the next invoice sequence number of the year.
+// The counter row stays locked until the transaction ends, so concurrent invoices never share a number and no
+// number is skipped when a transaction is rolled back.
+func (rpo *InvoiceRepository) NextSequence(ctx context.Context, tx *sql.Tx, year int) int64 {
+	query := `insert into invoice_sequences (year,last_number) values (?,1)
+	on duplicate key update last_number = last_number + 1`
+
+	_, err := tx.ExecContext(ctx, query, year)
+	if err != nil {
+		panic(err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "select last_number from invoice_sequences where year = ?", year)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var sequence int64
+	if rows.Next() {
+		err = rows.Scan(&sequence)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return sequence
+}
+
+func (rpo *InvoiceRepository) FindAll(ctx context.Context, tx *sql.Tx) []*domain.Invoice {
+	query := "select " + invoiceColumns + " from invoices where company_id = ? order by issued_at desc, number desc"
+
+	rows, err := tx.QueryContext(ctx, query, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var invoices []*domain.Invoice
+	for rows.Next() {
+		invoices = append(invoices, scanInvoice(rows))
+	}
+
+	return invoices
+}
+
+func (rpo *InvoiceRepository) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.Invoice, error) {
+	query := "select " + invoiceColumns + " from invoices where id = ? and company_id = ?"
+
+	rows, err := tx.QueryContext(ctx, query, id, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanInvoice(rows), nil
+	} else {
+		return new(domain.Invoice), errors.New("invoice not found")
+	}
+}
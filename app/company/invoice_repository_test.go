@@ -0,0 +1,41 @@
+package company
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestInvoiceFindByIdReadsTheTenantOnly(t *testing.T) {
+	ctx, tx, mock := beginTenantTx(t)
+
+	// The invoice of company B is not found in company A
+	mock.ExpectQuery(regexp.QuoteMeta("select "+invoiceColumns+" from invoices where id = ? and company_id = ?")).
+		WithArgs("invoice-b", "company-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "company_id", "payment_id", "plan_name", "amount",
+			"period_start", "period_end", "issued_at"}))
+
+	_, err := new(InvoiceRepository).FindById(ctx, tx, "invoice-b")
+	if err == nil {
+		t.Fatal("expected the invoice of company B not to be found")
+	}
+
+	// The invoice of company A is
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("select "+invoiceColumns+" from invoices where id = ? and company_id = ?")).
+		WithArgs("invoice-a", "company-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "company_id", "payment_id", "plan_name", "amount",
+			"period_start", "period_end", "issued_at"}).
+			AddRow("invoice-a", "INV/2026/000001", "company-a", "payment-a", "Pro", 150000, now, now, now))
+
+	invoice, err := new(InvoiceRepository).FindById(ctx, tx, "invoice-a")
+	if err != nil || invoice.CompanyId != "company-a" {
+		t.Fatalf("expected the invoice of company A, got %+v, %v", invoice, err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,151 @@
+package company
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-edash/domain"
+	"go-edash/exceptions"
+	"go-edash/pdf"
+	"go-edash/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type InvoiceService struct {
+	rpo  domain.InvoiceRepository
+	crpo domain.CompanyRepository
+	db   *sql.DB
+}
+
+// Issue issues the invoice of a paid payment, which subscribed the company to the plan for the period of the
+// subscription. Its number is INV-<year>-<sequence>, where the sequence starts over every year.
+func (svc *InvoiceService) Issue(ctx context.Context, tx *sql.Tx, payment *domain.Payment, plan *domain.Plan,
+	subscription *domain.Subscription) *domain.Invoice {
+	id, err := utils.UUIDGenerator()
+	if err != nil {
+		panic(err)
+	}
+
+	issuedAt := time.Now()
+	sequence := svc.rpo.NextSequence(ctx, tx, issuedAt.Year())
+
+	return svc.rpo.Create(ctx, tx, &domain.Invoice{
+		Id:          id,
+		Number:      fmt.Sprintf("INV-%d-%06d", issuedAt.Year(), sequence),
+		PaymentId:   payment.Id,
+		PlanName:    plan.Name,
+		Amount:      payment.Amount,
+		PeriodStart: subscription.PeriodStart,
+		PeriodEnd:   subscription.PeriodEnd,
+		IssuedAt:    issuedAt,
+	})
+}
+
+// GetInvoices returns the invoices of the company of the authenticated user, the latest first.
+func (svc *InvoiceService) GetInvoices(ctx context.Context) []domain.InvoiceResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	invoices := svc.rpo.FindAll(ctx, tx)
+
+	responses := make([]domain.InvoiceResponse, 0, len(invoices))
+	for _, invoice := range invoices {
+		responses = append(responses, domain.InvoiceResponse{
+			Id:          invoice.Id,
+			Number:      invoice.Number,
+			PlanName:    invoice.PlanName,
+			Amount:      invoice.Amount,
+			PeriodStart: invoice.PeriodStart,
+			PeriodEnd:   invoice.PeriodEnd,
+			IssuedAt:    invoice.IssuedAt,
+		})
+	}
+
+	return responses
+}
+
+// DownloadInvoice renders an invoice of the company of the authenticated user as a PDF file.
+// Invoices of other companies are reported as not found.
+func (svc *InvoiceService) DownloadInvoice(ctx context.Context, id string) domain.InvoiceDocument {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	invoice, errInvoice := svc.rpo.FindById(ctx, tx, id)
+	if errInvoice != nil {
+		panic(exceptions.NewNotFoundError(errInvoice.Error()))
+	}
+
+	company, errCompany := svc.crpo.FindCurrent(ctx, tx)
+	if errCompany != nil {
+		panic(exceptions.NewNotFoundError(errCompany.Error()))
+	}
+
+	return domain.InvoiceDocument{
+		FileName: invoice.Number + ".pdf",
+		Content:  renderInvoice(invoice, company),
+	}
+}
+
+// renderInvoice lays out an invoice on an A4 page.
+func renderInvoice(invoice *domain.Invoice, company *domain.Company) []byte {
+	const left, right = 56.0, pdf.PageWidth - 56
+
+	doc := pdf.New()
+
+	doc.Text(left, 80, 22, true, "INVOICE")
+	doc.TextRight(right, 72, 10, false, "Nomor: "+invoice.Number)
+	doc.TextRight(right, 86, 10, false, "Tanggal: "+invoice.IssuedAt.Format("02-01-2006"))
+	doc.Line(left, 104, right, 104)
+
+	doc.Text(left, 132, 10, true, "Ditagihkan kepada")
+	doc.Text(left, 150, 12, false, company.Name)
+	doc.Text(left, 166, 10, false, company.Description)
+
+	doc.Text(left, 214, 10, true, "Deskripsi")
+	doc.TextRight(right, 214, 10, true, "Jumlah")
+	doc.Line(left, 222, right, 222)
+
+	doc.Text(left, 242, 10, false, "Langganan EDash "+invoice.PlanName)
+	doc.Text(left, 256, 9, false, "Periode "+invoice.PeriodStart.Format("02-01-2006")+" s.d. "+
+		invoice.PeriodEnd.AddDate(0, 0, -1).Format("02-01-2006"))
+	doc.TextRight(right, 242, 10, false, formatRupiah(invoice.Amount))
+	doc.Line(left, 270, right, 270)
+
+	doc.Text(left, 290, 11, true, "Total")
+	doc.TextRight(right, 290, 11, true, formatRupiah(invoice.Amount))
+
+	doc.Text(left, 340, 10, true, "LUNAS")
+	doc.Text(left, 356, 9, false, "Dibayar melalui pembayaran "+invoice.PaymentId)
+
+	return doc.Bytes()
+}
+
+// formatRupiah formats an amount in rupiah, with dots separating the thousands (e.g. Rp 150.000).
+func formatRupiah(amount int64) string {
+	digits := strconv.FormatInt(amount, 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	var builder strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			builder.WriteByte('.')
+		}
+
+		builder.WriteRune(digit)
+	}
+
+	return sign + "Rp " + builder.String()
+}
@@ -0,0 +1,152 @@
+package company
+
+import (
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"go-edash/domain"
+	"go-edash/response"
+	"net/http"
+)
+
+type LifecycleHandler struct {
+	svc      domain.CompanyLifecycleService
+	validate *validator.Validate
+}
+
+// TransferOwnership is an HTTP handler function that starts handing the company over to another member.
+// It expects a JSON payload in the request body that conforms to the TransferOwnershipRequest struct.
+// The response status code is set to 200 OK.
+func (hdl *LifecycleHandler) TransferOwnership() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.TransferOwnershipRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		hdl.svc.TransferOwnership(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ConfirmOwnershipTransfer is an HTTP handler function that makes the authenticated user the owner of a company
+// once the OTP is confirmed.
+// It expects a JSON payload in the request body that conforms to the ConfirmOwnershipTransferRequest struct.
+// The response status code is set to 200 OK.
+func (hdl *LifecycleHandler) ConfirmOwnershipTransfer() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.ConfirmOwnershipTransferRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.ConfirmOwnershipTransfer(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// DeleteCompany is an HTTP handler function that deletes the company of the authenticated user.
+// The response status code is set to 200 OK.
+func (hdl *LifecycleHandler) DeleteCompany() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.DeleteCompany(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RestoreCompany is an HTTP handler function that cancels the deletion of a company owned by the authenticated user.
+// It expects a JSON payload in the request body that conforms to the RestoreCompanyRequest struct.
+// The response status code is set to 200 OK.
+func (hdl *LifecycleHandler) RestoreCompany() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.RestoreCompanyRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.RestoreCompany(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package company
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"go-edash/utils"
+	"time"
+)
+
+type LifecycleService struct {
+	crpo   domain.CompanyRepository
+	urpo   domain.UserRepository
+	mrpo   domain.MembershipRepository
+	irpo   domain.InvitationRepository
+	trpo   domain.OwnershipTransferRepository
+	srpo   domain.SessionRepository
+	osvc   domain.OtpService
+	db     *sql.DB
+	outbox domain.OutboxService
+}
+
+// companyRetention returns how long a deleted company is kept before it is purged, during which its owner can
+// restore it. It is read from COMPANY_RETENTION and defaults to 30 days.
+func companyRetention() time.Duration {
+	retention := viper.GetDuration("COMPANY_RETENTION")
+	if retention <= 0 {
+		return 30 * 24 * time.Hour
+	}
+
+	return retention
+}
+
+// ownedCompany returns the active company of the authenticated user, which the user must own.
+func (svc *LifecycleService) ownedCompany(ctx context.Context, tx *sql.Tx) *domain.Company {
+	principal := domain.MustPrincipal(ctx)
+
+	_, errMembership := svc.mrpo.FindMember(ctx, tx, principal.UserId)
+	if errMembership != nil {
+		panic(exceptions.NewNotFoundError("company not found"))
+	}
+
+	company, errCompany := svc.crpo.FindCurrent(ctx, tx)
+	if errCompany != nil {
+		panic(exceptions.NewNotFoundError(errCompany.Error()))
+	}
+
+	if company.OwnerId != principal.UserId {
+		panic(exceptions.NewForbiddenError("only the owner of the company is allowed"))
+	}
+
+	return company
+}
+
+// TransferOwnership starts handing the company of the authenticated user over to another member, and sends the
+// member the OTP confirming it. Only the owner of the company can hand it over.
+//
+// Starting another transfer cancels the pending one.
+func (svc *LifecycleService) TransferOwnership(ctx context.Context, request *domain.TransferOwnershipRequest) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	company := svc.ownedCompany(ctx, tx)
+
+	if request.MemberId == principal.UserId {
+		panic(exceptions.NewForbiddenError("you already own the company"))
+	}
+
+	_, errMembership := svc.mrpo.FindMember(ctx, tx, request.MemberId)
+	if errMembership != nil {
+		panic(exceptions.NewNotFoundError("member not found"))
+	}
+
+	owner, errOwner := svc.urpo.FindById(ctx, tx, principal.UserId)
+	if errOwner != nil {
+		panic(exceptions.NewNotFoundError(errOwner.Error()))
+	}
+
+	member, errMember := svc.urpo.FindById(ctx, tx, request.MemberId)
+	if errMember != nil {
+		panic(exceptions.NewNotFoundError(errMember.Error()))
+	}
+
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	svc.trpo.Invalidate(ctx, tx)
+
+	svc.trpo.Create(ctx, tx, &domain.OwnershipTransfer{
+		Id:         id,
+		FromUserId: owner.Id,
+		ToUserId:   member.Id,
+	})
+
+	otp, expiresAt := svc.osvc.Issue(ctx, tx, enums.OTP_OWNERSHIP_TRANSFER, id)
+
+	svc.outbox.EnqueueTemplate(ctx, tx, member.Email, member.FirstName+" "+member.LastName, member.Locale,
+		enums.EMAIL_OWNERSHIP_TRANSFER, map[string]any{
+			"Name":        member.FirstName,
+			"OwnerName":   owner.FirstName + " " + owner.LastName,
+			"CompanyName": company.Name,
+			"Otp":         otp,
+			"Minutes":     int(time.Until(expiresAt).Round(time.Minute).Minutes()),
+		})
+}
+
+// ConfirmOwnershipTransfer makes the authenticated user the owner of the company once the OTP of the pending
+// transfer to the user is confirmed. The transfer is only valid as long as the company is still owned by the member
+// who started it.
+//
+// A new owner who was a USER becomes an ADMIN, which the access token only carries once it has been refreshed.
+// The previous owner stays a member of the company and is notified by email.
+func (svc *LifecycleService) ConfirmOwnershipTransfer(ctx context.Context,
+	request *domain.ConfirmOwnershipTransferRequest) domain.MemberResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	ctx = domain.NewTenantContext(ctx, request.CompanyId)
+
+	membership, errMembership := svc.mrpo.FindMember(ctx, tx, principal.UserId)
+	if errMembership != nil {
+		panic(exceptions.NewNotFoundError("company not found"))
+	}
+
+	company, errCompany := svc.crpo.FindCurrent(ctx, tx)
+	if errCompany != nil {
+		panic(exceptions.NewNotFoundError(errCompany.Error()))
+	}
+
+	transfer, errTransfer := svc.trpo.FindPendingByUser(ctx, tx, principal.UserId)
+	if errTransfer != nil {
+		panic(exceptions.NewNotFoundError(errTransfer.Error()))
+	}
+
+	if transfer.FromUserId != company.OwnerId {
+		panic(exceptions.NewGoneError("ownership transfer is no longer valid"))
+	}
+
+	svc.osvc.Verify(ctx, tx, enums.OTP_OWNERSHIP_TRANSFER, transfer.Id, request.Otp)
+
+	svc.trpo.Invalidate(ctx, tx)
+
+	company.OwnerId = principal.UserId
+
+	svc.crpo.UpdateOwner(ctx, tx, company)
+
+	if membership.Role == enums.USER {
+		membership.Role = enums.ADMIN
+
+		svc.mrpo.UpdateRole(ctx, tx, membership)
+	}
+
+	owner, errOwner := svc.urpo.FindById(ctx, tx, principal.UserId)
+	if errOwner != nil {
+		panic(exceptions.NewNotFoundError(errOwner.Error()))
+	}
+
+	previous, errPrevious := svc.urpo.FindById(ctx, tx, transfer.FromUserId)
+	if errPrevious == nil {
+		svc.outbox.EnqueueTemplate(ctx, tx, previous.Email, previous.FirstName+" "+previous.LastName, previous.Locale,
+			enums.EMAIL_OWNERSHIP_TRANSFERRED, map[string]any{
+				"Name":        previous.FirstName,
+				"CompanyName": company.Name,
+				"OwnerName":   owner.FirstName + " " + owner.LastName,
+			})
+	}
+
+	return memberResponse(&domain.Member{
+		UserId:    owner.Id,
+		Email:     owner.Email,
+		FirstName: owner.FirstName,
+		LastName:  owner.LastName,
+		Role:      membership.Role,
+	})
+}
+
+// DeleteCompany deletes the company of the authenticated user. Only the owner of the company can delete it.
+//
+// Every member is detached from the company and has their sessions revoked, the owner included, and the pending
+// invitations are revoked. The data of the company is kept for the retention window, during which the owner can
+// restore the company, and is then purged for good.
+func (svc *LifecycleService) DeleteCompany(ctx context.Context) domain.CompanyDeletionResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	company := svc.ownedCompany(ctx, tx)
+
+	now := time.Now()
+	company.DeletedAt = sql.NullTime{Time: now, Valid: true}
+
+	svc.crpo.SoftDelete(ctx, tx, company)
+	svc.irpo.RevokePending(ctx, tx)
+	svc.trpo.Invalidate(ctx, tx)
+
+	for _, member := range svc.mrpo.FindMembers(ctx, tx) {
+		detachMember(ctx, tx, svc.urpo, svc.mrpo, svc.srpo, member.UserId)
+	}
+
+	return domain.CompanyDeletionResponse{
+		DeletedAt: now,
+		PurgeAt:   now.Add(companyRetention()),
+	}
+}
+
+// RestoreCompany cancels the deletion of a company owned by the authenticated user, as long as it has not been
+// purged yet. The owner becomes its ADMIN member again, and the company becomes the active company of an owner
+// left without any. The other members have to be invited again.
+func (svc *LifecycleService) RestoreCompany(ctx context.Context, request *domain.RestoreCompanyRequest) domain.CompanyResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	ctx = domain.NewTenantContext(ctx, request.CompanyId)
+
+	company, errCompany := svc.crpo.FindDeleted(ctx, tx)
+	if errCompany != nil || company.OwnerId != principal.UserId {
+		panic(exceptions.NewNotFoundError("company not found"))
+	}
+
+	if time.Now().After(company.DeletedAt.Time.Add(companyRetention())) {
+		panic(exceptions.NewGoneError("company can no longer be restored"))
+	}
+
+	company = svc.crpo.Restore(ctx, tx, company)
+
+	user, errUser := svc.urpo.FindById(ctx, tx, principal.UserId)
+	if errUser != nil {
+		panic(exceptions.NewNotFoundError(errUser.Error()))
+	}
+
+	svc.mrpo.Create(ctx, tx, &domain.Membership{
+		UserId:    user.Id,
+		CompanyId: company.Id,
+		Role:      enums.ADMIN,
+	})
+
+	if user.CompanyId == "" {
+		user.CompanyId = company.Id
+
+		if user.RegistrationStep == enums.EMAIL_VERIFIED {
+			user.RegistrationStep = enums.COMPANY_CREATED
+		}
+
+		svc.urpo.Update(ctx, tx, user)
+	}
+
+	return domain.CompanyResponse{
+		CompanyName:        company.Name,
+		CompanyDescription: company.Description,
+		CompanyCategory:    company.Category,
+		RequireTwoFactor:   company.RequireTwoFactor,
+	}
+}
+
+// PurgeDeletedCompanies removes for good the companies deleted longer than the retention window ago, along with
+// their operational data. Their invoices and payments are retained for accounting, detached from the company.
+//
+// Every company is purged in its own transaction, so that a company failing to be purged is logged and left for
+// the next run without holding back the others.
+func (svc *LifecycleService) PurgeDeletedCompanies(ctx context.Context) {
+	log := config.CreateLoggers(nil)
+
+	ids := svc.findPurgeable(ctx)
+
+	purged := 0
+	for _, id := range ids {
+		if svc.purge(domain.NewTenantContext(ctx, id)) {
+			purged++
+		}
+	}
+
+	log.Info(fmt.Sprintf("%d of %d companies purged", purged, len(ids)))
+}
+
+// findPurgeable returns the ids of the companies deleted longer than the retention window ago.
+func (svc *LifecycleService) findPurgeable(ctx context.Context) []string {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	return svc.crpo.FindPurgeable(ctx, tx, time.Now().Add(-companyRetention()))
+}
+
+// purge purges the company the context is scoped to in its own transaction. It reports whether the company has
+// been purged, and logs the failure when it has not.
+func (svc *LifecycleService) purge(ctx context.Context) (purged bool) {
+	defer func() {
+		if errPurge := recover(); errPurge != nil {
+			purged = false
+
+			config.CreateLoggers(nil).Error(fmt.Sprintf("company %s not purged: %v", domain.MustTenant(ctx),
+				errPurge))
+		}
+	}()
+
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	svc.crpo.Purge(ctx, tx)
+
+	return true
+}
@@ -0,0 +1,58 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"regexp"
+	"testing"
+)
+
+func TestPurgeDeletedCompaniesPurgesEveryCompanyOnItsOwn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	service := &LifecycleService{crpo: new(Repository), db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("select id from companies where deleted_at < ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("company-a").AddRow("company-b"))
+	mock.ExpectCommit()
+
+	// Company A fails to be purged and is rolled back alone
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("delete from " + purgedTables[0] + " where company_id = ?")).
+		WithArgs("company-a").
+		WillReturnError(errors.New("lock wait timeout exceeded"))
+	mock.ExpectRollback()
+
+	// Company B is purged regardless
+	mock.ExpectBegin()
+
+	for _, table := range purgedTables {
+		mock.ExpectExec(regexp.QuoteMeta("delete from " + table + " where company_id = ?")).
+			WithArgs("company-b").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	for _, table := range detachedTables {
+		mock.ExpectExec(regexp.QuoteMeta("update " + table + " set company_id = null where company_id = ?")).
+			WithArgs("company-b").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("delete from companies where id = ? and deleted_at is not null")).
+		WithArgs("company-b").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	service.PurgeDeletedCompanies(context.Background())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,64 @@
+package company
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+// OwnershipTransferRepository stores the ownership transfers of the company the context is scoped to.
+type OwnershipTransferRepository struct {
+}
+
+func (rpo *OwnershipTransferRepository) Create(ctx context.Context, tx *sql.Tx,
+	transfer *domain.OwnershipTransfer) *domain.OwnershipTransfer {
+	transfer.CompanyId = domain.MustTenant(ctx)
+
+	query := "insert into ownership_transfers (id,company_id,from_user_id,to_user_id) values (?,?,?,?)"
+
+	_, err := tx.ExecContext(ctx, query, transfer.Id, transfer.CompanyId, transfer.FromUserId, transfer.ToUserId)
+	if err != nil {
+		panic(err)
+	}
+
+	return transfer
+}
+
+// FindPendingByUser returns the latest ownership transfer of the company to the user that has not been
+// consumed yet.
+func (rpo *OwnershipTransferRepository) FindPendingByUser(ctx context.Context, tx *sql.Tx,
+	userId string) (*domain.OwnershipTransfer, error) {
+	query := `select id, company_id, from_user_id, to_user_id, consumed_at from ownership_transfers
+	where company_id = ? and to_user_id = ? and consumed_at is null order by created_at desc limit 1 for update`
+
+	rows, err := tx.QueryContext(ctx, query, domain.MustTenant(ctx), userId)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	transfer := new(domain.OwnershipTransfer)
+	if rows.Next() {
+		err = rows.Scan(&transfer.Id, &transfer.CompanyId, &transfer.FromUserId, &transfer.ToUserId,
+			&transfer.ConsumedAt)
+		if err != nil {
+			panic(err)
+		}
+
+		return transfer, nil
+	} else {
+		return transfer, errors.New("ownership transfer not found")
+	}
+}
+
+// Invalidate marks every pending ownership transfer of the company as consumed.
+func (rpo *OwnershipTransferRepository) Invalidate(ctx context.Context, tx *sql.Tx) {
+	query := "update ownership_transfers set consumed_at = current_timestamp where company_id = ? and consumed_at is null"
+
+	_, err := tx.ExecContext(ctx, query, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+}
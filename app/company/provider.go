@@ -4,6 +4,9 @@ import (
 	"database/sql"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
+	"go-edash/app/otp"
+	"go-edash/app/outbox"
+	"go-edash/app/subscription"
 	"go-edash/app/user"
 	"go-edash/domain"
 	"sync"
@@ -22,26 +25,94 @@ var (
 	crpo     *Repository
 	crpoOnce sync.Once
 
+	ihdl     *InvoiceHandler
+	ihdlOnce sync.Once
+
+	isvc     *InvoiceService
+	isvcOnce sync.Once
+
+	irpo     *InvoiceRepository
+	irpoOnce sync.Once
+
+	thdl     *TeamHandler
+	thdlOnce sync.Once
+
+	tsvc     *TeamService
+	tsvcOnce sync.Once
+
+	vrpo     *InvitationRepository
+	vrpoOnce sync.Once
+
+	lhdl     *LifecycleHandler
+	lhdlOnce sync.Once
+
+	lsvc     *LifecycleService
+	lsvcOnce sync.Once
+
+	trpo     *OwnershipTransferRepository
+	trpoOnce sync.Once
+
+	srpo     *user.SessionRepository
+	srpoOnce sync.Once
+
+	mrpo     *user.MembershipRepository
+	mrpoOnce sync.Once
+
 	urpo     *user.Repository
 	urpoOnce sync.Once
 
+	// InvoiceSet provides the InvoiceService, for the services settling payments to issue invoices with.
+	InvoiceSet = wire.NewSet(
+		ProvideInvoiceService,
+		ProvideInvoiceRepository,
+		ProvideCompanyRepository,
+		wire.Bind(new(domain.InvoiceService), new(*InvoiceService)),
+		wire.Bind(new(domain.InvoiceRepository), new(*InvoiceRepository)),
+		wire.Bind(new(domain.CompanyRepository), new(*Repository)),
+	)
+
 	ProviderSet = wire.NewSet(
+		InvoiceSet,
+		subscription.EntitlementSet,
+		outbox.ServiceSet,
+		otp.ProviderSet,
 		ProvideRouter,
 		ProvideHandler,
+		ProvideInvoiceHandler,
+		ProvideTeamHandler,
+		ProvideLifecycleHandler,
 		ProvideService,
-		ProvideCompanyRepository,
+		ProvideTeamService,
+		ProvideLifecycleService,
+		ProvideInvitationRepository,
+		ProvideOwnershipTransferRepository,
 		ProvideUserRepository,
+		ProvideSessionRepository,
+		ProvideMembershipRepository,
 		wire.Bind(new(domain.CompanyHandler), new(*Handler)),
+		wire.Bind(new(domain.InvoiceHandler), new(*InvoiceHandler)),
+		wire.Bind(new(domain.TeamHandler), new(*TeamHandler)),
+		wire.Bind(new(domain.CompanyLifecycleHandler), new(*LifecycleHandler)),
 		wire.Bind(new(domain.CompanyService), new(*Service)),
-		wire.Bind(new(domain.CompanyRepository), new(*Repository)),
+		wire.Bind(new(domain.TeamService), new(*TeamService)),
+		wire.Bind(new(domain.CompanyLifecycleService), new(*LifecycleService)),
+		wire.Bind(new(domain.InvitationRepository), new(*InvitationRepository)),
+		wire.Bind(new(domain.OwnershipTransferRepository), new(*OwnershipTransferRepository)),
 		wire.Bind(new(domain.UserRepository), new(*user.Repository)),
+		wire.Bind(new(domain.SessionRepository), new(*user.SessionRepository)),
+		wire.Bind(new(domain.MembershipRepository), new(*user.MembershipRepository)),
 	)
 )
 
-func ProvideRouter(hdl domain.CompanyHandler) *Router {
+func ProvideRouter(hdl domain.CompanyHandler, invoices domain.InvoiceHandler, team domain.TeamHandler,
+	lifecycle domain.CompanyLifecycleHandler, entitlements domain.EntitlementService) *Router {
 	routeOnce.Do(func() {
 		route = &Router{
-			hdl: hdl,
+			hdl:          hdl,
+			invoices:     invoices,
+			team:         team,
+			lifecycle:    lifecycle,
+			entitlements: entitlements,
 		}
 	})
 
@@ -59,11 +130,34 @@ func ProvideHandler(validate *validator.Validate, svc domain.CompanyService) *Ha
 	return hdl
 }
 
-func ProvideService(urpo domain.UserRepository, crpo domain.CompanyRepository, db *sql.DB) *Service {
+func ProvideInvoiceHandler(svc domain.InvoiceService) *InvoiceHandler {
+	ihdlOnce.Do(func() {
+		ihdl = &InvoiceHandler{
+			svc: svc,
+		}
+	})
+
+	return ihdl
+}
+
+func ProvideTeamHandler(validate *validator.Validate, svc domain.TeamService) *TeamHandler {
+	thdlOnce.Do(func() {
+		thdl = &TeamHandler{
+			svc:      svc,
+			validate: validate,
+		}
+	})
+
+	return thdl
+}
+
+func ProvideService(urpo domain.UserRepository, crpo domain.CompanyRepository, mrpo domain.MembershipRepository,
+	db *sql.DB) *Service {
 	svcOnce.Do(func() {
 		svc = &Service{
 			urpo: urpo,
 			crpo: crpo,
+			mrpo: mrpo,
 			db:   db,
 		}
 	})
@@ -71,6 +165,92 @@ func ProvideService(urpo domain.UserRepository, crpo domain.CompanyRepository, d
 	return svc
 }
 
+func ProvideTeamService(irpo domain.InvitationRepository, urpo domain.UserRepository, mrpo domain.MembershipRepository,
+	crpo domain.CompanyRepository, srpo domain.SessionRepository, entitlements domain.EntitlementService, db *sql.DB,
+	outbox domain.OutboxService) *TeamService {
+	tsvcOnce.Do(func() {
+		tsvc = &TeamService{
+			irpo:         irpo,
+			urpo:         urpo,
+			mrpo:         mrpo,
+			crpo:         crpo,
+			srpo:         srpo,
+			entitlements: entitlements,
+			db:           db,
+			outbox:       outbox,
+		}
+	})
+
+	return tsvc
+}
+
+func ProvideLifecycleHandler(validate *validator.Validate, svc domain.CompanyLifecycleService) *LifecycleHandler {
+	lhdlOnce.Do(func() {
+		lhdl = &LifecycleHandler{
+			svc:      svc,
+			validate: validate,
+		}
+	})
+
+	return lhdl
+}
+
+func ProvideLifecycleService(crpo domain.CompanyRepository, urpo domain.UserRepository,
+	mrpo domain.MembershipRepository, irpo domain.InvitationRepository, trpo domain.OwnershipTransferRepository,
+	srpo domain.SessionRepository, osvc domain.OtpService, db *sql.DB, outbox domain.OutboxService) *LifecycleService {
+	lsvcOnce.Do(func() {
+		lsvc = &LifecycleService{
+			crpo:   crpo,
+			urpo:   urpo,
+			mrpo:   mrpo,
+			irpo:   irpo,
+			trpo:   trpo,
+			srpo:   srpo,
+			osvc:   osvc,
+			db:     db,
+			outbox: outbox,
+		}
+	})
+
+	return lsvc
+}
+
+func ProvideInvoiceService(rpo domain.InvoiceRepository, crpo domain.CompanyRepository, db *sql.DB) *InvoiceService {
+	isvcOnce.Do(func() {
+		isvc = &InvoiceService{
+			rpo:  rpo,
+			crpo: crpo,
+			db:   db,
+		}
+	})
+
+	return isvc
+}
+
+func ProvideInvoiceRepository() *InvoiceRepository {
+	irpoOnce.Do(func() {
+		irpo = new(InvoiceRepository)
+	})
+
+	return irpo
+}
+
+func ProvideInvitationRepository() *InvitationRepository {
+	vrpoOnce.Do(func() {
+		vrpo = new(InvitationRepository)
+	})
+
+	return vrpo
+}
+
+func ProvideOwnershipTransferRepository() *OwnershipTransferRepository {
+	trpoOnce.Do(func() {
+		trpo = new(OwnershipTransferRepository)
+	})
+
+	return trpo
+}
+
 func ProvideCompanyRepository() *Repository {
 	crpoOnce.Do(func() {
 		crpo = new(Repository)
@@ -86,3 +266,19 @@ func ProvideUserRepository() *user.Repository {
 
 	return urpo
 }
+
+func ProvideSessionRepository() *user.SessionRepository {
+	srpoOnce.Do(func() {
+		srpo = new(user.SessionRepository)
+	})
+
+	return srpo
+}
+
+func ProvideMembershipRepository() *user.MembershipRepository {
+	mrpoOnce.Do(func() {
+		mrpo = new(user.MembershipRepository)
+	})
+
+	return mrpo
+}
@@ -5,69 +5,218 @@ import (
 	"database/sql"
 	"errors"
 	"go-edash/domain"
+	"go-edash/exceptions"
+	"time"
 )
 
 type Repository struct {
 }
 
-func getLastId(ctx context.Context, tx *sql.Tx) string {
-	var lastId string
+func (rpo *Repository) Create(ctx context.Context, tx *sql.Tx, company *domain.Company) *domain.Company {
+	query := "insert into companies (id,name,description,employee_count,owner_id) values (?,?,?,?,?)"
+
+	_, err := tx.ExecContext(ctx, query, company.Id, company.Name, company.Description, company.Category,
+		company.OwnerId)
+	if err != nil {
+		panic(err)
+	}
+
+	return company
+}
+
+// tenantOf returns the id of the company the context is scoped to, which the writes of a company are constrained
+// by. It panics with a NotFoundError when the company is not the one the context is scoped to.
+func tenantOf(ctx context.Context, company *domain.Company) string {
+	companyId := domain.MustTenant(ctx)
+	if company.Id != companyId {
+		panic(exceptions.NewNotFoundError("company not found"))
+	}
+
+	return companyId
+}
 
-	query := "select id from companies order by updated_at desc;"
+// Update updates the company the context is scoped to.
+func (rpo *Repository) Update(ctx context.Context, tx *sql.Tx, company *domain.Company) *domain.Company {
+	query := "update companies set name=?,description=?,employee_count=? where id = ?"
 
-	rows, err := tx.QueryContext(ctx, query)
+	result, err := tx.ExecContext(ctx, query, company.Name, company.Description, company.Category,
+		tenantOf(ctx, company))
 	if err != nil {
 		panic(err)
 	}
 
-	if rows.Next() {
-		err = rows.Scan(&lastId)
+	domain.MustAffectTenant(result, "company")
+
+	return company
+}
+
+// UpdateTwoFactorPolicy updates the two-factor policy of the company the context is scoped to.
+func (rpo *Repository) UpdateTwoFactorPolicy(ctx context.Context, tx *sql.Tx, company *domain.Company) *domain.Company {
+	query := "update companies set require_two_factor=? where id = ?"
+
+	result, err := tx.ExecContext(ctx, query, company.RequireTwoFactor, tenantOf(ctx, company))
+	if err != nil {
+		panic(err)
 	}
 
-	return lastId
+	domain.MustAffectTenant(result, "company")
+
+	return company
 }
 
-func (rpo *Repository) Create(ctx context.Context, tx *sql.Tx, company *domain.Company) *domain.Company {
-	query := "insert into companies (id,name,description,employee_count) values (?,?,?,?)"
+// UpdateOwner hands the company the context is scoped to over to another owner.
+func (rpo *Repository) UpdateOwner(ctx context.Context, tx *sql.Tx, company *domain.Company) *domain.Company {
+	query := "update companies set owner_id=? where id = ? and deleted_at is null"
 
-	_, err := tx.ExecContext(ctx, query, company.Id, company.Name, company.Description, company.Category)
+	result, err := tx.ExecContext(ctx, query, company.OwnerId, tenantOf(ctx, company))
 	if err != nil {
 		panic(err)
 	}
 
-	lastId := getLastId(ctx, tx)
+	domain.MustAffectTenant(result, "company")
+
+	return company
+}
+
+// SoftDelete marks the company the context is scoped to as deleted, keeping its data until it is purged.
+func (rpo *Repository) SoftDelete(ctx context.Context, tx *sql.Tx, company *domain.Company) *domain.Company {
+	query := "update companies set deleted_at=? where id = ? and deleted_at is null"
 
-	company.Id = lastId
+	result, err := tx.ExecContext(ctx, query, company.DeletedAt, tenantOf(ctx, company))
+	if err != nil {
+		panic(err)
+	}
+
+	domain.MustAffectTenant(result, "company")
 
 	return company
 }
 
-func (rpo *Repository) Update(ctx context.Context, tx *sql.Tx, company *domain.Company) *domain.Company {
-	query := "update companies set name=?,description=?,employee_count=? where id = ?"
+// Restore cancels the deletion of the company the context is scoped to.
+func (rpo *Repository) Restore(ctx context.Context, tx *sql.Tx, company *domain.Company) *domain.Company {
+	query := "update companies set deleted_at=null where id = ? and deleted_at is not null"
 
-	_, err := tx.ExecContext(ctx, query, company.Name, company.Description, company.Category, company.Id)
+	result, err := tx.ExecContext(ctx, query, tenantOf(ctx, company))
 	if err != nil {
 		panic(err)
 	}
 
+	domain.MustAffectTenant(result, "company")
+
+	company.DeletedAt = sql.NullTime{}
+
 	return company
 }
 
-func (rpo *Repository) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.Company, error) {
-	query := "select name,description,employee_count from companies where id =?"
+// companyColumns lists the columns read by findCompany, in the order they are scanned.
+const companyColumns = "id,name,description,employee_count,owner_id,require_two_factor,deleted_at"
 
-	rows, err := tx.QueryContext(ctx, query, id)
+func findCompany(ctx context.Context, tx *sql.Tx, query string, args ...any) (*domain.Company, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		panic(err)
 	}
 
+	defer rows.Close()
+
 	company := new(domain.Company)
 
 	if rows.Next() {
-		err = rows.Scan(&company.Name, &company.Description, &company.Category)
+		var ownerId sql.NullString
+
+		err = rows.Scan(&company.Id, &company.Name, &company.Description, &company.Category, &ownerId,
+			&company.RequireTwoFactor, &company.DeletedAt)
+		if err != nil {
+			panic(err)
+		}
+
+		company.OwnerId = ownerId.String
 
 		return company, nil
 	} else {
 		return company, errors.New("company not found")
 	}
 }
+
+// FindCurrent returns the company the context is scoped to, unless it has been deleted.
+func (rpo *Repository) FindCurrent(ctx context.Context, tx *sql.Tx) (*domain.Company, error) {
+	query := "select " + companyColumns + " from companies where id = ? and deleted_at is null"
+
+	return findCompany(ctx, tx, query, domain.MustTenant(ctx))
+}
+
+// FindDeleted returns the company the context is scoped to if it has been deleted and not purged yet.
+func (rpo *Repository) FindDeleted(ctx context.Context, tx *sql.Tx) (*domain.Company, error) {
+	query := "select " + companyColumns + " from companies where id = ? and deleted_at is not null for update"
+
+	return findCompany(ctx, tx, query, domain.MustTenant(ctx))
+}
+
+// FindPurgeable returns the ids of the companies deleted before the given time, across every company.
+func (rpo *Repository) FindPurgeable(ctx context.Context, tx *sql.Tx, before time.Time) []string {
+	query := "select id from companies where deleted_at < ?"
+
+	rows, err := tx.QueryContext(ctx, query, before)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+
+		err = rows.Scan(&id)
+		if err != nil {
+			panic(err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// purgedTables lists the tables holding the operational data of a company, in the order Purge empties them.
+var purgedTables = []string{
+	"ownership_transfers",
+	"invitations",
+	"subscriptions",
+	"company_members",
+}
+
+// detachedTables lists the tables holding the financial records of a company. They must be retained for
+// accounting, so Purge keeps them but detaches them from the company.
+var detachedTables = []string{
+	"invoices",
+	"payments",
+}
+
+// Purge removes the company the context is scoped to for good, along with its operational data. Its invoices and
+// payments are kept without a company.
+// Only deleted companies are purged.
+func (rpo *Repository) Purge(ctx context.Context, tx *sql.Tx) {
+	companyId := domain.MustTenant(ctx)
+
+	for _, table := range purgedTables {
+		_, err := tx.ExecContext(ctx, "delete from "+table+" where company_id = ?", companyId)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	for _, table := range detachedTables {
+		_, err := tx.ExecContext(ctx, "update "+table+" set company_id = null where company_id = ?", companyId)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "delete from companies where id = ? and deleted_at is not null", companyId)
+	if err != nil {
+		panic(err)
+	}
+
+	domain.MustAffectTenant(result, "company")
+}
@@ -0,0 +1,136 @@
+package company
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"go-edash/domain"
+	"go-edash/exceptions"
+	"regexp"
+	"testing"
+)
+
+// beginTenantTx returns a transaction on a mocked database and a context scoped to company A.
+func beginTenantTx(t *testing.T) (context.Context, *sql.Tx, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	mock.ExpectBegin()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return domain.NewTenantContext(context.Background(), "company-a"), tx, mock
+}
+
+// expectNotFound runs fn and fails the test unless it panics with a NotFoundError.
+func expectNotFound(t *testing.T, fn func()) {
+	t.Helper()
+
+	defer func() {
+		if _, ok := recover().(exceptions.NotFoundError); !ok {
+			t.Fatal("expected a NotFoundError")
+		}
+	}()
+
+	fn()
+}
+
+func TestFindCurrentReadsTheTenantOnly(t *testing.T) {
+	ctx, tx, mock := beginTenantTx(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("select " + companyColumns + " from companies where id = ? and deleted_at is null")).
+		WithArgs("company-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "employee_count", "owner_id",
+			"require_two_factor", "deleted_at"}).AddRow("company-a", "PT A", "", "1-10", "user-a", false, nil))
+
+	company, err := new(Repository).FindCurrent(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if company.Id != "company-a" {
+		t.Fatalf("expected company A, got %s", company.Id)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateRejectsAnotherCompany(t *testing.T) {
+	ctx, tx, mock := beginTenantTx(t)
+	rpo := new(Repository)
+
+	expectNotFound(t, func() {
+		rpo.Update(ctx, tx, &domain.Company{Id: "company-b", Name: "Taken over"})
+	})
+
+	expectNotFound(t, func() {
+		rpo.UpdateTwoFactorPolicy(ctx, tx, &domain.Company{Id: "company-b", RequireTwoFactor: true})
+	})
+
+	// Nothing has been written to the database
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateWritesTheTenantOnly(t *testing.T) {
+	ctx, tx, mock := beginTenantTx(t)
+	rpo := new(Repository)
+
+	mock.ExpectExec(regexp.QuoteMeta("update companies set name=?,description=?,employee_count=? where id = ?")).
+		WithArgs("PT A", "", "", "company-a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta("update companies set require_two_factor=? where id = ?")).
+		WithArgs(true, "company-a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rpo.Update(ctx, tx, &domain.Company{Id: "company-a", Name: "PT A"})
+	rpo.UpdateTwoFactorPolicy(ctx, tx, &domain.Company{Id: "company-a", RequireTwoFactor: true})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeRemovesTheTenantOnly(t *testing.T) {
+	ctx, tx, mock := beginTenantTx(t)
+
+	for _, table := range purgedTables {
+		mock.ExpectExec(regexp.QuoteMeta("delete from " + table + " where company_id = ?")).
+			WithArgs("company-a").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	for _, table := range detachedTables {
+		mock.ExpectExec(regexp.QuoteMeta("update " + table + " set company_id = null where company_id = ?")).
+			WithArgs("company-a").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	// Company A has not been deleted, so it is not purged
+	mock.ExpectExec(regexp.QuoteMeta("delete from companies where id = ? and deleted_at is not null")).
+		WithArgs("company-a").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	expectNotFound(t, func() {
+		new(Repository).Purge(ctx, tx)
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,11 +3,16 @@ package company
 import (
 	"github.com/go-chi/chi/v5"
 	"go-edash/domain"
+	"go-edash/enums"
 	"go-edash/middlewares"
 )
 
 type Router struct {
-	hdl domain.CompanyHandler
+	hdl          domain.CompanyHandler
+	invoices     domain.InvoiceHandler
+	team         domain.TeamHandler
+	lifecycle    domain.CompanyLifecycleHandler
+	entitlements domain.EntitlementService
 }
 
 func (router *Router) InitializeRoute(rtr *chi.Mux) {
@@ -15,8 +20,51 @@ func (router *Router) InitializeRoute(rtr *chi.Mux) {
 		route.Use(middlewares.AuthorizationCheckMiddleware)
 		route.Use(middlewares.VerifyTokenMiddleware)
 
-		route.Get("/show", router.hdl.GetCompany())
-		route.Post("/save", router.hdl.StoreCompany())
-		route.Post("/update", router.hdl.UpdateCompany())
+		route.With(middlewares.RequireOnboardingStep(enums.EMAIL_VERIFIED),
+			middlewares.RequirePermission(enums.COMPANY_CREATE)).Post("/save", router.hdl.StoreCompany())
+		route.Post("/restore", router.lifecycle.RestoreCompany())
+		route.Post("/ownership/confirm", router.lifecycle.ConfirmOwnershipTransfer())
+
+		route.Group(func(onboarded chi.Router) {
+			onboarded.Use(middlewares.RequireOnboardingStep(enums.COMPANY_CREATED))
+
+			onboarded.With(middlewares.RequirePermission(enums.COMPANY_READ)).Get("/show", router.hdl.GetCompany())
+			onboarded.With(middlewares.RequirePermission(enums.COMPANY_UPDATE)).Post("/update", router.hdl.UpdateCompany())
+			onboarded.With(middlewares.RequirePaidAccess(router.entitlements), middlewares.RequirePermission(enums.COMPANY_UPDATE)).
+				Post("/two-factor-policy", router.hdl.UpdateTwoFactorPolicy())
+			onboarded.With(middlewares.RequirePermission(enums.COMPANY_UPDATE)).
+				Post("/ownership/transfer", router.lifecycle.TransferOwnership())
+			onboarded.With(middlewares.RequirePermission(enums.COMPANY_UPDATE)).Post("/delete", router.lifecycle.DeleteCompany())
+
+			onboarded.Group(func(billing chi.Router) {
+				billing.Use(middlewares.RequirePermission(enums.SUBSCRIPTION_READ))
+
+				billing.Get("/invoices", router.invoices.GetInvoices())
+				billing.Get("/invoices/{id}", router.invoices.DownloadInvoice())
+			})
+
+			onboarded.With(middlewares.RequirePermission(enums.MEMBER_READ)).Get("/members", router.team.GetMembers())
+
+			onboarded.Group(func(team chi.Router) {
+				team.Use(middlewares.RequirePermission(enums.MEMBER_MANAGE))
+
+				team.Post("/members/{id}/remove", router.team.RemoveMember())
+				team.Get("/invitations", router.team.GetInvitations())
+				team.Post("/invitations", router.team.InviteMember())
+				team.Post("/invitations/{id}/revoke", router.team.RevokeInvitation())
+			})
+		})
+	})
+
+	rtr.Route("/api/invitation", func(route chi.Router) {
+		route.Get("/", router.team.PreviewInvitation())
+		route.Post("/register", router.team.RegisterWithInvitation())
+		route.Post("/decline", router.team.DeclineInvitation())
+
+		route.Group(func(secure chi.Router) {
+			secure.Use(middlewares.AuthorizationCheckMiddleware)
+			secure.Use(middlewares.VerifyTokenMiddleware)
+			secure.Post("/accept", router.team.AcceptInvitation())
+		})
 	})
 }
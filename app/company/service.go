@@ -3,8 +3,8 @@ package company
 import (
 	"context"
 	"database/sql"
-	"github.com/golang-jwt/jwt/v5"
 	"go-edash/domain"
+	"go-edash/enums"
 	"go-edash/exceptions"
 	"go-edash/utils"
 )
@@ -12,9 +12,32 @@ import (
 type Service struct {
 	crpo domain.CompanyRepository
 	urpo domain.UserRepository
+	mrpo domain.MembershipRepository
 	db   *sql.DB
 }
 
+// activeCompany returns the active company of the authenticated user, which the request is scoped to.
+// A user who is no longer a member of that company is answered as if it did not exist.
+func (svc *Service) activeCompany(ctx context.Context, tx *sql.Tx) *domain.Company {
+	principal := domain.MustPrincipal(ctx)
+
+	_, errMembership := svc.mrpo.FindMember(ctx, tx, principal.UserId)
+	if errMembership != nil {
+		panic(exceptions.NewNotFoundError("company not found"))
+	}
+
+	company, errCompany := svc.crpo.FindCurrent(ctx, tx)
+	if errCompany != nil {
+		panic(exceptions.NewNotFoundError(errCompany.Error()))
+	}
+
+	return company
+}
+
+// SaveCompany creates a company owned by the authenticated user and makes the user its ADMIN member. The new
+// company becomes the active company of the user.
+// A user at the EMAIL_VERIFIED onboarding step moves to the COMPANY_CREATED step. The access token only carries
+// both once it has been refreshed.
 func (svc *Service) SaveCompany(ctx context.Context, request *domain.SaveCompanyRequest) domain.CompanyResponse {
 	tx, err := svc.db.Begin()
 	if err != nil {
@@ -23,30 +46,47 @@ func (svc *Service) SaveCompany(ctx context.Context, request *domain.SaveCompany
 
 	defer utils.CommitRollback(tx)
 
-	claims := ctx.Value("claims").(jwt.MapClaims)
-	sub := claims["sub"]
+	principal := domain.MustPrincipal(ctx)
+
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
 
 	company := &domain.Company{
+		Id:          id,
 		Name:        request.CompanyName,
 		Description: request.CompanyDescription,
 		Category:    request.CompanyCategory,
+		OwnerId:     principal.UserId,
 	}
 
 	company = svc.crpo.Create(ctx, tx, company)
 
-	user, errUser := svc.urpo.FindByEmail(ctx, tx, sub.(string))
+	user, errUser := svc.urpo.FindById(ctx, tx, principal.UserId)
 	if errUser != nil {
 		panic(exceptions.NewNotFoundError(errUser.Error()))
 	}
 
+	svc.mrpo.Create(ctx, tx, &domain.Membership{
+		UserId:    user.Id,
+		CompanyId: company.Id,
+		Role:      enums.ADMIN,
+	})
+
 	user.CompanyId = company.Id
 
+	if user.RegistrationStep == enums.EMAIL_VERIFIED {
+		user.RegistrationStep = enums.COMPANY_CREATED
+	}
+
 	svc.urpo.Update(ctx, tx, user)
 
 	return domain.CompanyResponse{
 		CompanyName:        company.Name,
 		CompanyDescription: company.Description,
 		CompanyCategory:    company.Category,
+		RequireTwoFactor:   company.RequireTwoFactor,
 	}
 }
 
@@ -58,20 +98,7 @@ func (svc *Service) UpdateCompany(ctx context.Context, request *domain.UpdateCom
 
 	defer utils.CommitRollback(tx)
 
-	claims := ctx.Value("claims").(jwt.MapClaims)
-	sub := claims["sub"]
-
-	user, errUser := svc.urpo.FindByEmail(ctx, tx, sub.(string))
-	if errUser != nil {
-		panic(exceptions.NewNotFoundError(errUser.Error()))
-	}
-
-	companyId := user.CompanyId
-
-	company, errCompany := svc.crpo.FindById(ctx, tx, companyId)
-	if errCompany != nil {
-		panic(exceptions.NewNotFoundError(errCompany.Error()))
-	}
+	company := svc.activeCompany(ctx, tx)
 
 	company.Name = request.CompanyName
 	company.Description = request.CompanyDescription
@@ -83,9 +110,11 @@ func (svc *Service) UpdateCompany(ctx context.Context, request *domain.UpdateCom
 		CompanyName:        company.Name,
 		CompanyDescription: company.Description,
 		CompanyCategory:    company.Category,
+		RequireTwoFactor:   company.RequireTwoFactor,
 	}
 }
 
+// GetCompanyInformation returns the active company of the authenticated user.
 func (svc *Service) GetCompanyInformation(ctx context.Context) domain.CompanyResponse {
 	tx, err := svc.db.Begin()
 	if err != nil {
@@ -94,24 +123,36 @@ func (svc *Service) GetCompanyInformation(ctx context.Context) domain.CompanyRes
 
 	defer utils.CommitRollback(tx)
 
-	claims := ctx.Value("claims").(jwt.MapClaims)
-	sub := claims["sub"]
+	company := svc.activeCompany(ctx, tx)
 
-	user, errUser := svc.urpo.FindByEmail(ctx, tx, sub.(string))
-	if errUser != nil {
-		panic(exceptions.NewNotFoundError(errUser.Error()))
+	return domain.CompanyResponse{
+		CompanyName:        company.Name,
+		CompanyDescription: company.Description,
+		CompanyCategory:    company.Category,
+		RequireTwoFactor:   company.RequireTwoFactor,
 	}
+}
 
-	companyId := user.CompanyId
-
-	company, errCompany := svc.crpo.FindById(ctx, tx, companyId)
-	if errCompany != nil {
-		panic(exceptions.NewNotFoundError(errCompany.Error()))
+// UpdateTwoFactorPolicy sets whether every member of the company must use two-factor authentication.
+// Members without two-factor authentication have to enroll the next time they log in.
+func (svc *Service) UpdateTwoFactorPolicy(ctx context.Context, request *domain.TwoFactorPolicyRequest) domain.CompanyResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
 	}
 
+	defer utils.CommitRollback(tx)
+
+	company := svc.activeCompany(ctx, tx)
+
+	company.RequireTwoFactor = *request.RequireTwoFactor
+
+	company = svc.crpo.UpdateTwoFactorPolicy(ctx, tx, company)
+
 	return domain.CompanyResponse{
 		CompanyName:        company.Name,
 		CompanyDescription: company.Description,
 		CompanyCategory:    company.Category,
+		RequireTwoFactor:   company.RequireTwoFactor,
 	}
 }
@@ -0,0 +1,285 @@
+package company
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"go-edash/domain"
+	"go-edash/response"
+	"net/http"
+)
+
+type TeamHandler struct {
+	svc      domain.TeamService
+	validate *validator.Validate
+}
+
+// GetMembers is an HTTP handler function that returns the members of the company of the authenticated user.
+// The response status code is set to 200 OK.
+func (hdl *TeamHandler) GetMembers() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.GetMembers(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RemoveMember is an HTTP handler function that removes the member named by the id URL parameter from the company.
+// The response status code is set to 200 OK.
+func (hdl *TeamHandler) RemoveMember() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		hdl.svc.RemoveMember(ctx, chi.URLParam(request, "id"))
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// InviteMember is an HTTP handler function that invites the owner of an email to join the company.
+// It expects a JSON payload in the request body that conforms to the InviteMemberRequest struct.
+// It returns a JSON response with the invitation and the status code set to 201 Created.
+func (hdl *TeamHandler) InviteMember() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.InviteMemberRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.InviteMember(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusCreated,
+			Status: http.StatusText(http.StatusCreated),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// GetInvitations is an HTTP handler function that returns the pending invitations of the company.
+// The response status code is set to 200 OK.
+func (hdl *TeamHandler) GetInvitations() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.GetInvitations(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RevokeInvitation is an HTTP handler function that revokes the invitation named by the id URL parameter.
+// The response status code is set to 200 OK.
+func (hdl *TeamHandler) RevokeInvitation() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		hdl.svc.RevokeInvitation(ctx, chi.URLParam(request, "id"))
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// PreviewInvitation is an HTTP handler function that returns what an invitation invites to.
+// It expects the token of the invitation link to be passed as a query parameter in the request URL.
+// The response status code is set to 200 OK.
+func (hdl *TeamHandler) PreviewInvitation() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.PreviewInvitation(ctx, request.URL.Query().Get("token"))
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// AcceptInvitation is an HTTP handler function that makes the authenticated user a member of the company of an invitation.
+// It expects a JSON payload in the request body that conforms to the InvitationTokenRequest struct.
+// The response status code is set to 200 OK.
+func (hdl *TeamHandler) AcceptInvitation() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.InvitationTokenRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.AcceptInvitation(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterWithInvitation is an HTTP handler function that creates the account of an invitee and adds it to the company.
+// It expects a JSON payload in the request body that conforms to the RegisterWithInvitationRequest struct.
+// It returns a JSON response with the new member and the status code set to 201 Created.
+func (hdl *TeamHandler) RegisterWithInvitation() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.RegisterWithInvitationRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.RegisterWithInvitation(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusCreated,
+			Status: http.StatusText(http.StatusCreated),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// DeclineInvitation is an HTTP handler function that declines an invitation.
+// It expects a JSON payload in the request body that conforms to the InvitationTokenRequest struct.
+// The response status code is set to 200 OK.
+func (hdl *TeamHandler) DeclineInvitation() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.InvitationTokenRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		hdl.svc.DeclineInvitation(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
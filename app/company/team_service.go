@@ -0,0 +1,483 @@
+package company
+
+import (
+	"context"
+	"crypto/hmac"
+	"database/sql"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"go-edash/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TeamService struct {
+	irpo         domain.InvitationRepository
+	urpo         domain.UserRepository
+	mrpo         domain.MembershipRepository
+	crpo         domain.CompanyRepository
+	srpo         domain.SessionRepository
+	entitlements domain.EntitlementService
+	db           *sql.DB
+	outbox       domain.OutboxService
+}
+
+// invitationTTL returns how long an invitation link stays valid.
+// It is read from INVITATION_TTL and defaults to 7 days.
+func invitationTTL() time.Duration {
+	ttl := viper.GetDuration("INVITATION_TTL")
+	if ttl <= 0 {
+		return 7 * 24 * time.Hour
+	}
+
+	return ttl
+}
+
+// signInvitation returns the token of the link of an invitation, which is <company id>.<id>.<expiry>.<signature>.
+// The signature is the HMAC of the rest of the token under INVITATION_SECRET, so none of it can be altered. The
+// application does not start without INVITATION_SECRET.
+func signInvitation(invitation *domain.Invitation) string {
+	payload := invitation.CompanyId + "." + invitation.Id + "." + strconv.FormatInt(invitation.ExpiresAt.Unix(), 10)
+
+	return payload + "." + utils.HmacToken(payload, viper.GetString("INVITATION_SECRET"))
+}
+
+// verifyInvitation checks the signature and the expiry of an invitation token and returns the ids of the company
+// and of the invitation.
+func verifyInvitation(token string) (string, string) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		panic(exceptions.NewNotMatchedError("invalid invitation token"))
+	}
+
+	payload := parts[0] + "." + parts[1] + "." + parts[2]
+	signature := utils.HmacToken(payload, viper.GetString("INVITATION_SECRET"))
+	if !hmac.Equal([]byte(signature), []byte(parts[3])) {
+		panic(exceptions.NewNotMatchedError("invalid invitation token"))
+	}
+
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		panic(exceptions.NewNotMatchedError("invalid invitation token"))
+	}
+
+	if time.Now().After(time.Unix(expiry, 0)) {
+		panic(exceptions.NewGoneError("invitation expired"))
+	}
+
+	return parts[0], parts[1]
+}
+
+// findInvitation returns the pending invitation of a token, locked until the transaction ends, along with the
+// context scoped to the company of the invitation.
+func (svc *TeamService) findInvitation(ctx context.Context, tx *sql.Tx, token string) (context.Context, *domain.Invitation) {
+	companyId, id := verifyInvitation(token)
+
+	ctx = domain.NewTenantContext(ctx, companyId)
+
+	invitation, err := svc.irpo.FindById(ctx, tx, id)
+	if err != nil {
+		panic(exceptions.NewNotFoundError(err.Error()))
+	}
+
+	if invitation.Status != enums.INVITATION_PENDING {
+		panic(exceptions.NewGoneError("invitation is no longer pending"))
+	}
+
+	return ctx, invitation
+}
+
+func memberResponse(member *domain.Member) domain.MemberResponse {
+	return domain.MemberResponse{
+		Id:        member.UserId,
+		Email:     member.Email,
+		FirstName: member.FirstName,
+		LastName:  member.LastName,
+		Role:      member.Role,
+	}
+}
+
+func invitationResponse(invitation *domain.Invitation) domain.InvitationResponse {
+	return domain.InvitationResponse{
+		Id:        invitation.Id,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		Status:    invitation.Status,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+}
+
+// GetMembers returns the members of the company of the authenticated user.
+func (svc *TeamService) GetMembers(ctx context.Context) []domain.MemberResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	members := svc.mrpo.FindMembers(ctx, tx)
+
+	responses := make([]domain.MemberResponse, 0, len(members))
+	for _, member := range members {
+		responses = append(responses, memberResponse(member))
+	}
+
+	return responses
+}
+
+// RemoveMember removes a member from the company of the authenticated user and revokes every session of the member.
+//
+// Users cannot remove themselves, the owner of the company cannot be removed, and only a SUPER ADMIN can remove
+// another SUPER ADMIN. When the company was the active one of the removed member, another company of the member
+// becomes active. A member left without any company goes back to the EMAIL_VERIFIED onboarding step, from where a
+// company of their own can be created.
+func (svc *TeamService) RemoveMember(ctx context.Context, id string) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	membership, errMembership := svc.mrpo.FindMember(ctx, tx, id)
+	if errMembership != nil {
+		panic(exceptions.NewNotFoundError("member not found"))
+	}
+
+	if membership.UserId == principal.UserId {
+		panic(exceptions.NewForbiddenError("you cannot remove yourself"))
+	}
+
+	if membership.Role == enums.SUPERADMIN && principal.Role != enums.SUPERADMIN {
+		panic(exceptions.NewForbiddenError("only a super admin can remove a super admin"))
+	}
+
+	company, errCompany := svc.crpo.FindCurrent(ctx, tx)
+	if errCompany != nil {
+		panic(exceptions.NewNotFoundError(errCompany.Error()))
+	}
+
+	if membership.UserId == company.OwnerId {
+		panic(exceptions.NewForbiddenError("the owner of the company cannot be removed"))
+	}
+
+	detachMember(ctx, tx, svc.urpo, svc.mrpo, svc.srpo, membership.UserId)
+}
+
+// detachMember removes the user from the company the context is scoped to and revokes every session and access
+// token of the user, whose tokens still carry the company and the role the user had in it.
+//
+// When the company was the active one of the user, another company of the user becomes active. A user left
+// without any company goes back to the EMAIL_VERIFIED onboarding step, from where a company of their own can be
+// created.
+func detachMember(ctx context.Context, tx *sql.Tx, urpo domain.UserRepository, mrpo domain.MembershipRepository,
+	srpo domain.SessionRepository, userId string) {
+	mrpo.Delete(ctx, tx, userId)
+
+	member, errMember := urpo.FindById(ctx, tx, userId)
+	if errMember != nil {
+		panic(exceptions.NewNotFoundError(errMember.Error()))
+	}
+
+	if member.CompanyId == domain.MustTenant(ctx) {
+		member.CompanyId = ""
+
+		if remaining := mrpo.FindAllByUser(ctx, tx, member.Id); len(remaining) > 0 {
+			member.CompanyId = remaining[0].CompanyId
+		} else {
+			member.RegistrationStep = enums.EMAIL_VERIFIED
+		}
+
+		urpo.Update(ctx, tx, member)
+	}
+
+	srpo.RevokeByUser(ctx, tx, member.Id)
+
+	errRevoke := config.RevokeSubject(ctx, tx, member.Id)
+	if errRevoke != nil {
+		panic(errRevoke)
+	}
+}
+
+// InviteMember invites the owner of an email to join the company of the authenticated user, as a USER unless
+// another role is requested, and sends them the signed link of the invitation.
+//
+// Inviting the same email again revokes the previous invitation. Pending invitations hold a seat of the plan,
+// so a company cannot invite more members than its plan allows.
+func (svc *TeamService) InviteMember(ctx context.Context, request *domain.InviteMemberRequest) domain.InvitationResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	company, errCompany := svc.crpo.FindCurrent(ctx, tx)
+	if errCompany != nil {
+		panic(exceptions.NewNotFoundError(errCompany.Error()))
+	}
+
+	inviter, errInviter := svc.urpo.FindById(ctx, tx, principal.UserId)
+	if errInviter != nil {
+		panic(exceptions.NewNotFoundError(errInviter.Error()))
+	}
+
+	invitee, errInvitee := svc.urpo.FindByEmail(ctx, tx, request.Email)
+	if errInvitee == nil {
+		if _, errMembership := svc.mrpo.FindMember(ctx, tx, invitee.Id); errMembership == nil {
+			panic(exceptions.NewDuplicateError("user is already a member of the company"))
+		}
+	}
+
+	svc.irpo.RevokePendingByEmail(ctx, tx, request.Email)
+
+	now := time.Now()
+	used := len(svc.mrpo.FindMembers(ctx, tx)) + len(svc.irpo.FindPending(ctx, tx, now))
+	svc.entitlements.EnsureCapacity(ctx, tx, enums.MEMBER_SEATS, int64(used))
+
+	role := request.Role
+	if role == "" {
+		role = enums.USER
+	}
+
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	invitation := svc.irpo.Create(ctx, tx, &domain.Invitation{
+		Id:        id,
+		Email:     request.Email,
+		Role:      role,
+		InvitedBy: inviter.Id,
+		Status:    enums.INVITATION_PENDING,
+		ExpiresAt: now.Add(invitationTTL()),
+	})
+
+	link := viper.GetString("APP_FRONTEND_URL") + "/invitation?token=" + signInvitation(invitation)
+
+	// The invitee reads the invitation in their own locale when they already have an account,
+	// and in the locale of the inviter otherwise.
+	locale := inviter.Locale
+	if errInvitee == nil {
+		locale = invitee.Locale
+	}
+
+	svc.outbox.EnqueueTemplate(ctx, tx, invitation.Email, invitation.Email, locale, enums.EMAIL_INVITATION,
+		map[string]any{
+			"InviterName": inviter.FirstName + " " + inviter.LastName,
+			"CompanyName": company.Name,
+			"Role":        invitation.Role,
+			"Link":        link,
+			"ExpiresAt":   invitation.ExpiresAt.Format("02-01-2006 15:04"),
+		})
+
+	return invitationResponse(invitation)
+}
+
+// GetInvitations returns the invitations of the company of the authenticated user that can still be accepted.
+func (svc *TeamService) GetInvitations(ctx context.Context) []domain.InvitationResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	invitations := svc.irpo.FindPending(ctx, tx, time.Now())
+
+	responses := make([]domain.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		responses = append(responses, invitationResponse(invitation))
+	}
+
+	return responses
+}
+
+// RevokeInvitation revokes a pending invitation of the company of the authenticated user, so that its link
+// cannot be used anymore.
+func (svc *TeamService) RevokeInvitation(ctx context.Context, id string) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	invitation, errInvitation := svc.irpo.FindById(ctx, tx, id)
+	if errInvitation != nil {
+		panic(exceptions.NewNotFoundError(errInvitation.Error()))
+	}
+
+	if invitation.Status != enums.INVITATION_PENDING {
+		panic(exceptions.NewGoneError("invitation is no longer pending"))
+	}
+
+	invitation.Status = enums.INVITATION_REVOKED
+
+	svc.irpo.UpdateStatus(ctx, tx, invitation)
+}
+
+// PreviewInvitation returns what an invitation token invites to, for the invitee to decide whether to accept it.
+func (svc *TeamService) PreviewInvitation(ctx context.Context, token string) domain.InvitationPreviewResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	ctx, invitation := svc.findInvitation(ctx, tx, token)
+
+	company, errCompany := svc.crpo.FindCurrent(ctx, tx)
+	if errCompany != nil {
+		panic(exceptions.NewNotFoundError(errCompany.Error()))
+	}
+
+	return domain.InvitationPreviewResponse{
+		CompanyName: company.Name,
+		Email:       invitation.Email,
+		Role:        invitation.Role,
+		ExpiresAt:   invitation.ExpiresAt,
+	}
+}
+
+// AcceptInvitation makes the authenticated user a member of the company of an invitation, with the role of the
+// invitation. The invitation must have been sent to the email of the user.
+// The company becomes the active company of a user who had none, which the access token only carries once it has
+// been refreshed. Other users switch to it.
+func (svc *TeamService) AcceptInvitation(ctx context.Context, request *domain.InvitationTokenRequest) domain.MemberResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	ctx, invitation := svc.findInvitation(ctx, tx, request.Token)
+
+	user, errUser := svc.urpo.FindById(ctx, tx, principal.UserId)
+	if errUser != nil {
+		panic(exceptions.NewNotFoundError(errUser.Error()))
+	}
+
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		panic(exceptions.NewForbiddenError("invitation was sent to another email"))
+	}
+
+	if _, errMembership := svc.mrpo.FindMember(ctx, tx, user.Id); errMembership == nil {
+		panic(exceptions.NewDuplicateError("user is already a member of the company"))
+	}
+
+	svc.entitlements.EnsureSeatAvailable(ctx, tx)
+
+	svc.mrpo.Create(ctx, tx, &domain.Membership{
+		UserId:    user.Id,
+		CompanyId: invitation.CompanyId,
+		Role:      invitation.Role,
+	})
+
+	if user.CompanyId == "" {
+		user.CompanyId = invitation.CompanyId
+		user.RegistrationStep = enums.DONE
+
+		svc.urpo.Update(ctx, tx, user)
+	}
+
+	invitation.Status = enums.INVITATION_ACCEPTED
+
+	svc.irpo.UpdateStatus(ctx, tx, invitation)
+
+	return memberResponse(&domain.Member{
+		UserId:    user.Id,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Role:      invitation.Role,
+	})
+}
+
+// RegisterWithInvitation creates the account of an invitee and makes it a member of the company of the invitation.
+//
+// The email of the account is the one the invitation was sent to, which the link proves to be owned by the invitee,
+// so the account skips the onboarding. Invitees who already have an account log in and accept the invitation instead.
+func (svc *TeamService) RegisterWithInvitation(ctx context.Context, request *domain.RegisterWithInvitationRequest) domain.MemberResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	ctx, invitation := svc.findInvitation(ctx, tx, request.Token)
+
+	_, errFind := svc.urpo.FindByEmail(ctx, tx, invitation.Email)
+	if errFind == nil {
+		panic(exceptions.NewDuplicateError("email already exists"))
+	}
+
+	svc.entitlements.EnsureSeatAvailable(ctx, tx)
+
+	hash, errHash := utils.Hash(request.Password)
+	if errHash != nil {
+		panic(errHash)
+	}
+
+	user := svc.urpo.Create(ctx, tx, &domain.User{
+		Email:            invitation.Email,
+		Password:         hash,
+		FirstName:        request.FirstName,
+		LastName:         request.LastName,
+		Role:             invitation.Role,
+		RegistrationStep: enums.DONE,
+		Locale:           request.Locale,
+	})
+
+	svc.mrpo.Create(ctx, tx, &domain.Membership{
+		UserId:    user.Id,
+		CompanyId: invitation.CompanyId,
+		Role:      invitation.Role,
+	})
+
+	user.CompanyId = invitation.CompanyId
+
+	svc.urpo.Update(ctx, tx, user)
+
+	invitation.Status = enums.INVITATION_ACCEPTED
+
+	svc.irpo.UpdateStatus(ctx, tx, invitation)
+
+	return memberResponse(&domain.Member{
+		UserId:    user.Id,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Role:      invitation.Role,
+	})
+}
+
+// DeclineInvitation declines an invitation, so that its link cannot be used anymore.
+func (svc *TeamService) DeclineInvitation(ctx context.Context, request *domain.InvitationTokenRequest) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	ctx, invitation := svc.findInvitation(ctx, tx, request.Token)
+	invitation.Status = enums.INVITATION_DECLINED
+
+	svc.irpo.UpdateStatus(ctx, tx, invitation)
+}
@@ -0,0 +1,144 @@
+package company
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"database/sql"
+	"encoding/pem"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/middlewares"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// userStore serves a single user, in place of the users table.
+type userStore struct {
+	domain.UserRepository
+	user *domain.User
+}
+
+func (store *userStore) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.User, error) {
+	return store.user, nil
+}
+
+func (store *userStore) Update(ctx context.Context, tx *sql.Tx, user *domain.User) *domain.User {
+	store.user = user
+
+	return user
+}
+
+// membershipStore records the memberships deleted by the test.
+type membershipStore struct {
+	domain.MembershipRepository
+	deleted []string
+}
+
+func (store *membershipStore) Delete(ctx context.Context, tx *sql.Tx, userId string) {
+	store.deleted = append(store.deleted, userId)
+}
+
+func (store *membershipStore) FindAllByUser(ctx context.Context, tx *sql.Tx, userId string) []*domain.Membership {
+	return nil
+}
+
+// sessionStore records the users whose sessions are revoked by the test.
+type sessionStore struct {
+	domain.SessionRepository
+	revoked []string
+}
+
+func (store *sessionStore) RevokeByUser(ctx context.Context, tx *sql.Tx, userId string) {
+	store.revoked = append(store.revoked, userId)
+}
+
+// loadSigningKey generates an Ed25519 signing key for the tokens minted by the test.
+func loadSigningKey(t *testing.T) {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+
+	block := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	err = os.WriteFile(filepath.Join(dir, "test-key.pem"), block, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Set("JWT_KEYS_DIR", dir)
+	viper.Set("JWT_ACTIVE_KID", "test-key")
+	defer viper.Set("JWT_KEYS_DIR", "")
+	defer viper.Set("JWT_ACTIVE_KID", "")
+
+	if err = config.LoadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetachMemberRevokesAccessTokens(t *testing.T) {
+	loadSigningKey(t)
+
+	token, err := config.GenerateToken(&config.JwtParameters{
+		UserId:           "user-b",
+		Email:            "member@example.com",
+		Role:             enums.ADMIN,
+		CompanyId:        "company-a",
+		SessionId:        "session-b",
+		RegistrationStep: enums.DONE,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	protected := middlewares.VerifyTokenMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	request := func() int {
+		recorder := httptest.NewRecorder()
+
+		r := httptest.NewRequest(http.MethodGet, "/api/company/members", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		protected.ServeHTTP(recorder, r)
+
+		return recorder.Code
+	}
+
+	if code := request(); code != http.StatusNoContent {
+		t.Fatalf("expected the token of the member to be accepted, got %d", code)
+	}
+
+	users := &userStore{user: &domain.User{Id: "user-b", CompanyId: "company-a", RegistrationStep: enums.DONE}}
+	memberships := new(membershipStore)
+	sessions := new(sessionStore)
+
+	detachMember(domain.NewTenantContext(context.Background(), "company-a"), nil, users, memberships, sessions,
+		"user-b")
+
+	if len(memberships.deleted) != 1 || len(sessions.revoked) != 1 {
+		t.Fatalf("expected the membership and the sessions of the member to be removed, got %v and %v",
+			memberships.deleted, sessions.revoked)
+	}
+
+	if code := request(); code != http.StatusUnauthorized {
+		t.Fatalf("expected the token of the removed member to be rejected, got %d", code)
+	}
+}
@@ -7,8 +7,13 @@ import (
 	"database/sql"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
+	"go-edash/domain"
 )
 
-func Wire(validate *validator.Validate, db *sql.DB) *Router {
+func Wire(validate *validator.Validate, db *sql.DB, mail domain.Mailer, messenger domain.Messenger) *Router {
+	panic(wire.Build(ProviderSet))
+}
+
+func WireLifecycle(db *sql.DB, mail domain.Mailer, messenger domain.Messenger) *LifecycleService {
 	panic(wire.Build(ProviderSet))
 }
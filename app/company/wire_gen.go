@@ -9,15 +9,54 @@ package company
 import (
 	"database/sql"
 	"github.com/go-playground/validator/v10"
+	"go-edash/app/otp"
+	"go-edash/app/outbox"
+	"go-edash/app/subscription"
+	"go-edash/domain"
 )
 
 // Injectors from wire.go:
 
-func Wire(validate *validator.Validate, db *sql.DB) *Router {
+func Wire(validate *validator.Validate, db *sql.DB, mail domain.Mailer, messenger domain.Messenger) *Router {
 	repository := ProvideUserRepository()
 	companyRepository := ProvideCompanyRepository()
-	service := ProvideService(repository, companyRepository, db)
+	membershipRepository := ProvideMembershipRepository()
+	service := ProvideService(repository, companyRepository, membershipRepository, db)
 	handler := ProvideHandler(validate, service)
-	router := ProvideRouter(handler)
+	invoiceRepository := ProvideInvoiceRepository()
+	invoiceService := ProvideInvoiceService(invoiceRepository, companyRepository, db)
+	invoiceHandler := ProvideInvoiceHandler(invoiceService)
+	invitationRepository := ProvideInvitationRepository()
+	sessionRepository := ProvideSessionRepository()
+	planRepository := subscription.ProvidePlanRepository()
+	subscriptionRepository := subscription.ProvideRepository()
+	entitlementService := subscription.ProvideEntitlementService(planRepository, subscriptionRepository, db)
+	outboxRepository := outbox.ProvideRepository()
+	suppressionRepository := outbox.ProvideSuppressionRepository()
+	outboxService := outbox.ProvideService(outboxRepository, suppressionRepository, db, mail, messenger)
+	teamService := ProvideTeamService(invitationRepository, repository, membershipRepository, companyRepository, sessionRepository, entitlementService, db, outboxService)
+	teamHandler := ProvideTeamHandler(validate, teamService)
+	ownershipTransferRepository := ProvideOwnershipTransferRepository()
+	otpRepository := otp.ProvideRepository()
+	otpService := otp.ProvideService(otpRepository, db)
+	lifecycleService := ProvideLifecycleService(companyRepository, repository, membershipRepository, invitationRepository, ownershipTransferRepository, sessionRepository, otpService, db, outboxService)
+	lifecycleHandler := ProvideLifecycleHandler(validate, lifecycleService)
+	router := ProvideRouter(handler, invoiceHandler, teamHandler, lifecycleHandler, entitlementService)
 	return router
 }
+
+func WireLifecycle(db *sql.DB, mail domain.Mailer, messenger domain.Messenger) *LifecycleService {
+	companyRepository := ProvideCompanyRepository()
+	repository := ProvideUserRepository()
+	membershipRepository := ProvideMembershipRepository()
+	invitationRepository := ProvideInvitationRepository()
+	ownershipTransferRepository := ProvideOwnershipTransferRepository()
+	sessionRepository := ProvideSessionRepository()
+	otpRepository := otp.ProvideRepository()
+	otpService := otp.ProvideService(otpRepository, db)
+	outboxRepository := outbox.ProvideRepository()
+	suppressionRepository := outbox.ProvideSuppressionRepository()
+	outboxService := outbox.ProvideService(outboxRepository, suppressionRepository, db, mail, messenger)
+	lifecycleService := ProvideLifecycleService(companyRepository, repository, membershipRepository, invitationRepository, ownershipTransferRepository, sessionRepository, otpService, db, outboxService)
+	return lifecycleService
+}
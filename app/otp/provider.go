@@ -0,0 +1,42 @@
+package otp
+
+import (
+	"database/sql"
+	"github.com/google/wire"
+	"go-edash/domain"
+	"sync"
+)
+
+var (
+	svc     *Service
+	svcOnce sync.Once
+
+	rpo     *Repository
+	rpoOnce sync.Once
+
+	ProviderSet = wire.NewSet(
+		ProvideService,
+		ProvideRepository,
+		wire.Bind(new(domain.OtpService), new(*Service)),
+		wire.Bind(new(domain.OtpRepository), new(*Repository)),
+	)
+)
+
+func ProvideService(rpo domain.OtpRepository, db *sql.DB) *Service {
+	svcOnce.Do(func() {
+		svc = &Service{
+			rpo: rpo,
+			db:  db,
+		}
+	})
+
+	return svc
+}
+
+func ProvideRepository() *Repository {
+	rpoOnce.Do(func() {
+		rpo = new(Repository)
+	})
+
+	return rpo
+}
@@ -0,0 +1,86 @@
+package otp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+	"go-edash/enums"
+)
+
+type Repository struct {
+}
+
+func (rpo *Repository) Create(ctx context.Context, tx *sql.Tx, otp *domain.Otp) *domain.Otp {
+	query := "insert into otps (id,purpose,target,code_hash,expires_at) values (?,?,?,?,?)"
+
+	_, err := tx.ExecContext(ctx, query, otp.Id, otp.Purpose, otp.Target, otp.CodeHash, otp.ExpiresAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return otp
+}
+
+// FindPending returns the latest code issued for the purpose and target that has not been consumed yet.
+func (rpo *Repository) FindPending(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string) (*domain.Otp, error) {
+	query := `select id, purpose, target, code_hash, expires_at, attempts, consumed_at from otps
+	where purpose = ? and target = ? and consumed_at is null order by created_at desc limit 1`
+
+	rows, err := tx.QueryContext(ctx, query, purpose, target)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	otp := new(domain.Otp)
+	if rows.Next() {
+		err = rows.Scan(&otp.Id, &otp.Purpose, &otp.Target, &otp.CodeHash, &otp.ExpiresAt, &otp.Attempts,
+			&otp.ConsumedAt)
+		if err != nil {
+			panic(err)
+		}
+
+		return otp, nil
+	} else {
+		return otp, errors.New("otp not found")
+	}
+}
+
+func (rpo *Repository) IncrementAttempts(ctx context.Context, tx *sql.Tx, id string) {
+	query := "update otps set attempts = attempts + 1 where id = ?"
+
+	_, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Consume marks the code as consumed.
+// It returns false when the code had already been consumed, e.g. by a concurrent request.
+func (rpo *Repository) Consume(ctx context.Context, tx *sql.Tx, id string) bool {
+	query := "update otps set consumed_at = current_timestamp where id = ? and consumed_at is null"
+
+	result, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+
+	return affected == 1
+}
+
+// Invalidate marks every pending code of the purpose and target as consumed.
+func (rpo *Repository) Invalidate(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string) {
+	query := "update otps set consumed_at = current_timestamp where purpose = ? and target = ? and consumed_at is null"
+
+	_, err := tx.ExecContext(ctx, query, purpose, target)
+	if err != nil {
+		panic(err)
+	}
+}
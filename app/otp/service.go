@@ -0,0 +1,121 @@
+package otp
+
+import (
+	"context"
+	"crypto/hmac"
+	"database/sql"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"go-edash/utils"
+	"time"
+)
+
+// Service issues and verifies the one-time passwords sent to the users.
+//
+// Codes are stored in the otps table as an HMAC keyed with OTP_SECRET, so that a leaked table cannot be brute
+// forced offline; the application does not start without it. Each code has an absolute expiry, is bound to a
+// purpose and a target (the user or the pending operation it confirms), can only be consumed once and is
+// rejected after too many wrong attempts.
+type Service struct {
+	rpo domain.OtpRepository
+	db  *sql.DB
+}
+
+// ttl returns how long a code stays valid, read from OTP_TTL and defaulting to 10 minutes.
+func ttl() time.Duration {
+	value := viper.GetDuration("OTP_TTL")
+	if value <= 0 {
+		return 10 * time.Minute
+	}
+
+	return value
+}
+
+// maxAttempts returns the number of wrong attempts after which a code is rejected, read from OTP_MAX_ATTEMPTS
+// and defaulting to 5.
+func maxAttempts() int {
+	value := viper.GetInt("OTP_MAX_ATTEMPTS")
+	if value <= 0 {
+		return 5
+	}
+
+	return value
+}
+
+func hashCode(code string) string {
+	return utils.HmacToken(code, viper.GetString("OTP_SECRET"))
+}
+
+// Issue generates a new code for the purpose and target, invalidating the codes issued before it.
+// It returns the plain code, to be sent to the user, and its expiry.
+func (svc *Service) Issue(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string) (string, time.Time) {
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	code, errCode := utils.OTPGenerator(6)
+	if errCode != nil {
+		panic(errCode)
+	}
+
+	svc.rpo.Invalidate(ctx, tx, purpose, target)
+
+	otp := svc.rpo.Create(ctx, tx, &domain.Otp{
+		Id:        id,
+		Purpose:   purpose,
+		Target:    target,
+		CodeHash:  hashCode(code),
+		ExpiresAt: time.Now().Add(ttl()),
+	})
+
+	return code, otp.ExpiresAt
+}
+
+// Verify checks a code issued for the purpose and target, and consumes it within the transaction of the caller.
+//
+// A wrong code is counted in its own transaction, so that the attempt is recorded even though the transaction
+// of the caller is rolled back.
+func (svc *Service) Verify(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string, code string) {
+	otp, errFind := svc.rpo.FindPending(ctx, tx, purpose, target)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	if time.Now().After(otp.ExpiresAt) {
+		panic(exceptions.NewGoneError("otp expired"))
+	}
+
+	if otp.Attempts >= maxAttempts() {
+		panic(exceptions.NewGoneError("too many wrong attempts, request a new otp"))
+	}
+
+	if !hmac.Equal([]byte(otp.CodeHash), []byte(hashCode(code))) {
+		svc.recordFailure(ctx, otp.Id)
+
+		panic(exceptions.NewNotMatchedError("otp not matched"))
+	}
+
+	if !svc.rpo.Consume(ctx, tx, otp.Id) {
+		panic(exceptions.NewGoneError("otp already used"))
+	}
+}
+
+// recordFailure counts a wrong attempt on the code.
+func (svc *Service) recordFailure(ctx context.Context, id string) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	svc.rpo.IncrementAttempts(ctx, tx, id)
+}
+
+// Invalidate invalidates every pending code of the purpose and target.
+func (svc *Service) Invalidate(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string) {
+	svc.rpo.Invalidate(ctx, tx, purpose, target)
+}
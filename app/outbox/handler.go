@@ -0,0 +1,74 @@
+package outbox
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/response"
+	"net/http"
+)
+
+type Handler struct {
+	svc      domain.OutboxService
+	validate *validator.Validate
+}
+
+// GetMessages is an HTTP handler function that returns the latest emails of the outbox with the status given by
+// the status query parameter, DEAD by default.
+// The response status code is set to 200 OK.
+func (hdl *Handler) GetMessages() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := &domain.OutboxMessagesRequest{
+			Status: enums.OutboxStatus(request.URL.Query().Get("status")),
+		}
+
+		err := hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.GetMessages(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RetryMessage is an HTTP handler function that queues the email named by the id URL parameter again.
+// The response status code is set to 200 OK.
+func (hdl *Handler) RetryMessage() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.RetryMessage(ctx, chi.URLParam(request, "id"))
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"database/sql"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+	"go-edash/domain"
+	"sync"
+)
+
+var (
+	route     *Router
+	routeOnce sync.Once
+
+	hdl     *Handler
+	hdlOnce sync.Once
+
+	svc     *Service
+	svcOnce sync.Once
+
+	rpo     *Repository
+	rpoOnce sync.Once
+
+	shdl     *SuppressionHandler
+	shdlOnce sync.Once
+
+	ssvc     *SuppressionService
+	ssvcOnce sync.Once
+
+	srpo     *SuppressionRepository
+	srpoOnce sync.Once
+
+	// ServiceSet provides the OutboxService, for the services sending emails to queue them with, and the
+	// EmailSuppressionRepository it consults.
+	ServiceSet = wire.NewSet(
+		ProvideService,
+		ProvideRepository,
+		ProvideSuppressionRepository,
+		wire.Bind(new(domain.OutboxService), new(*Service)),
+		wire.Bind(new(domain.OutboxRepository), new(*Repository)),
+		wire.Bind(new(domain.EmailSuppressionRepository), new(*SuppressionRepository)),
+	)
+
+	ProviderSet = wire.NewSet(
+		ServiceSet,
+		ProvideRouter,
+		ProvideHandler,
+		ProvideSuppressionHandler,
+		ProvideSuppressionService,
+		wire.Bind(new(domain.OutboxHandler), new(*Handler)),
+		wire.Bind(new(domain.EmailSuppressionHandler), new(*SuppressionHandler)),
+		wire.Bind(new(domain.EmailSuppressionService), new(*SuppressionService)),
+	)
+)
+
+func ProvideRouter(hdl domain.OutboxHandler, shdl domain.EmailSuppressionHandler) *Router {
+	routeOnce.Do(func() {
+		route = &Router{
+			hdl:  hdl,
+			shdl: shdl,
+		}
+	})
+
+	return route
+}
+
+func ProvideHandler(validate *validator.Validate, svc domain.OutboxService) *Handler {
+	hdlOnce.Do(func() {
+		hdl = &Handler{
+			svc:      svc,
+			validate: validate,
+		}
+	})
+
+	return hdl
+}
+
+func ProvideService(rpo domain.OutboxRepository, srpo domain.EmailSuppressionRepository, db *sql.DB,
+	mail domain.Mailer, messenger domain.Messenger) *Service {
+	svcOnce.Do(func() {
+		svc = &Service{
+			rpo:       rpo,
+			srpo:      srpo,
+			db:        db,
+			mail:      mail,
+			messenger: messenger,
+		}
+	})
+
+	return svc
+}
+
+func ProvideRepository() *Repository {
+	rpoOnce.Do(func() {
+		rpo = new(Repository)
+	})
+
+	return rpo
+}
+
+func ProvideSuppressionHandler(svc domain.EmailSuppressionService) *SuppressionHandler {
+	shdlOnce.Do(func() {
+		shdl = &SuppressionHandler{
+			svc: svc,
+		}
+	})
+
+	return shdl
+}
+
+func ProvideSuppressionService(rpo domain.EmailSuppressionRepository, db *sql.DB) *SuppressionService {
+	ssvcOnce.Do(func() {
+		ssvc = &SuppressionService{
+			rpo: rpo,
+			db:  db,
+		}
+	})
+
+	return ssvc
+}
+
+func ProvideSuppressionRepository() *SuppressionRepository {
+	srpoOnce.Do(func() {
+		srpo = new(SuppressionRepository)
+	})
+
+	return srpo
+}
@@ -0,0 +1,178 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+	"go-edash/enums"
+	"time"
+)
+
+// Repository stores the emails of the outbox. The outbox belongs to the application, not to a company.
+type Repository struct {
+}
+
+// outboxColumns lists the columns read by scanMessage, in the order they are scanned.
+const outboxColumns = `id, to_email, to_name, subject, text, html, channel, to_phone, locale, otp, status, attempts,
+	next_attempt_at, last_error, created_at, sent_at`
+
+// scanMessage scans the current row into a message. The text column holds the text of the email, or of the short
+// text message when the row has a channel.
+func scanMessage(rows *sql.Rows) *domain.OutboxMessage {
+	message := new(domain.OutboxMessage)
+
+	var toEmail, toName, subject, html, channel, toPhone, locale, otp, lastError sql.NullString
+	var text string
+
+	err := rows.Scan(&message.Id, &toEmail, &toName, &subject, &text, &html, &channel, &toPhone, &locale, &otp,
+		&message.Status, &message.Attempts, &message.NextAttemptAt, &lastError, &message.CreatedAt, &message.SentAt)
+	if err != nil {
+		panic(err)
+	}
+
+	if channel.Valid {
+		message.Channel = enums.MessageChannel(channel.String)
+		message.Message = domain.Message{
+			ToPhone: toPhone.String,
+			Locale:  enums.Locale(locale.String),
+			Text:    text,
+			Otp:     otp.String,
+		}
+	} else {
+		message.Email = domain.Email{
+			ToEmail: toEmail.String,
+			ToName:  toName.String,
+			Subject: subject.String,
+			Text:    text,
+			Html:    html.String,
+		}
+	}
+
+	message.LastError = lastError.String
+
+	return message
+}
+
+// nullString returns a NULL for an empty value.
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// body returns the text, HTML and one-time password of the message, as they are stored.
+func body(message *domain.OutboxMessage) (string, sql.NullString, sql.NullString) {
+	if message.Channel != "" {
+		return message.Message.Text, sql.NullString{}, nullString(message.Message.Otp)
+	}
+
+	return message.Email.Text, nullString(message.Email.Html), sql.NullString{}
+}
+
+func scanMessages(rows *sql.Rows) []*domain.OutboxMessage {
+	defer rows.Close()
+
+	var messages []*domain.OutboxMessage
+	for rows.Next() {
+		messages = append(messages, scanMessage(rows))
+	}
+
+	return messages
+}
+
+func (rpo *Repository) Create(ctx context.Context, tx *sql.Tx, message *domain.OutboxMessage) *domain.OutboxMessage {
+	query := `insert into email_outbox (id,to_email,to_name,subject,text,html,channel,to_phone,locale,otp,status,
+	attempts,next_attempt_at) values (?,?,?,?,?,?,?,?,?,?,?,?,?)`
+
+	text, html, otp := body(message)
+
+	_, err := tx.ExecContext(ctx, query, message.Id, nullString(message.Email.ToEmail),
+		nullString(message.Email.ToName), nullString(message.Email.Subject), text, html,
+		nullString(string(message.Channel)), nullString(message.Message.ToPhone),
+		nullString(string(message.Message.Locale)), otp, message.Status, message.Attempts, message.NextAttemptAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return message
+}
+
+// Update records the outcome of a delivery attempt, or the reset of the message by a retry.
+// The content of the message is written as well, since it is dropped once the message is done with.
+func (rpo *Repository) Update(ctx context.Context, tx *sql.Tx, message *domain.OutboxMessage) *domain.OutboxMessage {
+	query := `update email_outbox set status=?,attempts=?,next_attempt_at=?,last_error=?,sent_at=?,text=?,html=?,
+	otp=? where id = ?`
+
+	text, html, otp := body(message)
+
+	_, err := tx.ExecContext(ctx, query, message.Status, message.Attempts, message.NextAttemptAt,
+		nullString(message.LastError), message.SentAt, text, html, otp, message.Id)
+	if err != nil {
+		panic(err)
+	}
+
+	return message
+}
+
+// DeleteFinishedBefore deletes the messages that are SENT, SUPPRESSED or DEAD and were created before the given
+// time. It returns the number of messages deleted.
+func (rpo *Repository) DeleteFinishedBefore(ctx context.Context, tx *sql.Tx, before time.Time) int64 {
+	query := "delete from email_outbox where status in (?,?,?) and created_at < ?"
+
+	result, err := tx.ExecContext(ctx, query, enums.OUTBOX_SENT, enums.OUTBOX_SUPPRESSED, enums.OUTBOX_DEAD, before)
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+
+	return affected
+}
+
+// FindById returns the message and locks it until the transaction ends.
+func (rpo *Repository) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.OutboxMessage, error) {
+	query := "select " + outboxColumns + " from email_outbox where id = ? for update"
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanMessage(rows), nil
+	} else {
+		return new(domain.OutboxMessage), errors.New("outbox message not found")
+	}
+}
+
+// FindAllByStatus returns the latest messages with the status, up to the limit.
+func (rpo *Repository) FindAllByStatus(ctx context.Context, tx *sql.Tx, status enums.OutboxStatus,
+	limit int) []*domain.OutboxMessage {
+	query := "select " + outboxColumns + " from email_outbox where status = ? order by created_at desc limit ?"
+
+	rows, err := tx.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		panic(err)
+	}
+
+	return scanMessages(rows)
+}
+
+// ClaimDue returns the pending messages due at the given time, oldest first and up to the limit.
+// They are locked until the transaction ends, and skipped by the other dispatchers meanwhile. The caller postpones
+// them before committing to keep them claimed afterward.
+func (rpo *Repository) ClaimDue(ctx context.Context, tx *sql.Tx, at time.Time, limit int) []*domain.OutboxMessage {
+	query := "select " + outboxColumns + ` from email_outbox where status = ? and next_attempt_at <= ?
+	order by next_attempt_at limit ? for update skip locked`
+
+	rows, err := tx.QueryContext(ctx, query, enums.OUTBOX_PENDING, at, limit)
+	if err != nil {
+		panic(err)
+	}
+
+	return scanMessages(rows)
+}
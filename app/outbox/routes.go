@@ -0,0 +1,30 @@
+package outbox
+
+import (
+	"github.com/go-chi/chi/v5"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/middlewares"
+)
+
+type Router struct {
+	hdl  domain.OutboxHandler
+	shdl domain.EmailSuppressionHandler
+}
+
+func (router *Router) InitializeRoute(rtr *chi.Mux) {
+	rtr.Route("/api/outbox", func(route chi.Router) {
+		route.Post("/webhook/mailjet", router.shdl.MailjetWebhook())
+
+		route.Group(func(secure chi.Router) {
+			secure.Use(middlewares.AuthorizationCheckMiddleware)
+			secure.Use(middlewares.VerifyTokenMiddleware)
+			secure.Use(middlewares.RequirePermission(enums.OUTBOX_MANAGE))
+
+			secure.Get("/", router.hdl.GetMessages())
+			secure.Post("/{id}/retry", router.hdl.RetryMessage())
+			secure.Get("/suppressions", router.shdl.GetSuppressions())
+			secure.Delete("/suppressions/{email}", router.shdl.DeleteSuppression())
+		})
+	})
+}
@@ -0,0 +1,352 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"go-edash/mailer"
+	"go-edash/utils"
+	"time"
+)
+
+// Service is the transactional outbox of the emails.
+//
+// The services queue their emails with Enqueue in their own transaction, so that an email is only sent when the
+// change it tells about is committed. Dispatch then delivers the due emails through the Mailer. A failed delivery
+// is retried with an exponential backoff, and the email is dead-lettered as DEAD after too many attempts, from
+// where an operator can retry it. Delivery is at least once: an email sent right before the dispatcher stops may
+// be sent again.
+//
+// An email to an address of the suppression list is not sent and ends SUPPRESSED. The content of an email is
+// dropped once it is SENT or SUPPRESSED, and the emails that are done with are purged by PurgeMessages.
+//
+// The short text messages to phone numbers, queued with EnqueueMessage, go through the outbox the same way and
+// are delivered through the Messenger. The suppression list only applies to the emails.
+type Service struct {
+	rpo       domain.OutboxRepository
+	srpo      domain.EmailSuppressionRepository
+	db        *sql.DB
+	mail      domain.Mailer
+	messenger domain.Messenger
+}
+
+// batchSize returns the number of emails delivered by a single dispatch, read from OUTBOX_BATCH_SIZE and
+// defaulting to 20.
+func batchSize() int {
+	value := viper.GetInt("OUTBOX_BATCH_SIZE")
+	if value <= 0 {
+		return 20
+	}
+
+	return value
+}
+
+// maxAttempts returns the number of failed deliveries after which an email is dead-lettered, read from
+// OUTBOX_MAX_ATTEMPTS and defaulting to 8.
+func maxAttempts() int {
+	value := viper.GetInt("OUTBOX_MAX_ATTEMPTS")
+	if value <= 0 {
+		return 8
+	}
+
+	return value
+}
+
+// backoff returns how long to wait before delivering an email again after the given number of failed attempts.
+//
+// The first retry waits OUTBOX_RETRY_BACKOFF (defaults to 1 minute), and the wait doubles with every further
+// failure, up to OUTBOX_MAX_BACKOFF (defaults to 1 hour).
+func backoff(attempts int) time.Duration {
+	base := viper.GetDuration("OUTBOX_RETRY_BACKOFF")
+	if base <= 0 {
+		base = time.Minute
+	}
+
+	maximum := viper.GetDuration("OUTBOX_MAX_BACKOFF")
+	if maximum <= 0 {
+		maximum = time.Hour
+	}
+
+	duration := base
+	for i := 1; i < attempts && duration < maximum; i++ {
+		duration *= 2
+	}
+
+	if duration > maximum {
+		return maximum
+	}
+
+	return duration
+}
+
+func messageResponse(message *domain.OutboxMessage) domain.OutboxMessageResponse {
+	return domain.OutboxMessageResponse{
+		Id:            message.Id,
+		ToEmail:       message.Email.ToEmail,
+		ToPhone:       message.Message.ToPhone,
+		Channel:       message.Channel,
+		Subject:       message.Email.Subject,
+		Status:        message.Status,
+		Attempts:      message.Attempts,
+		NextAttemptAt: message.NextAttemptAt,
+		LastError:     message.LastError,
+		CreatedAt:     message.CreatedAt,
+	}
+}
+
+// Enqueue queues the email in the transaction of the caller. It is only sent once the transaction is committed.
+func (svc *Service) Enqueue(ctx context.Context, tx *sql.Tx, email *domain.Email) {
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	svc.rpo.Create(ctx, tx, &domain.OutboxMessage{
+		Id:            id,
+		Email:         *email,
+		Status:        enums.OUTBOX_PENDING,
+		NextAttemptAt: time.Now(),
+	})
+}
+
+// claimTimeout returns how long the emails claimed by a dispatcher are left alone by the other dispatchers, read
+// from OUTBOX_CLAIM_TIMEOUT and defaulting to 5 minutes. An email claimed by a dispatcher that stops before
+// recording its delivery is sent again once its claim times out.
+func claimTimeout() time.Duration {
+	value := viper.GetDuration("OUTBOX_CLAIM_TIMEOUT")
+	if value <= 0 {
+		return 5 * time.Minute
+	}
+
+	return value
+}
+
+// retention returns how long the emails that are done with, SENT, SUPPRESSED or DEAD, are kept, read from
+// OUTBOX_RETENTION and defaulting to 30 days.
+func retention() time.Duration {
+	value := viper.GetDuration("OUTBOX_RETENTION")
+	if value <= 0 {
+		return 30 * 24 * time.Hour
+	}
+
+	return value
+}
+
+// clearBody drops the content of an email that will not be sent anymore, which the outbox has no reason to keep.
+// Its subject is kept to tell the emails apart.
+func clearBody(message *domain.OutboxMessage) {
+	message.Email.Text = ""
+	message.Email.Html = ""
+	message.Message.Text = ""
+	message.Message.Otp = ""
+}
+
+// EnqueueTemplate renders the email template in the locale and queues the email to the address in the
+// transaction of the caller, like Enqueue.
+func (svc *Service) EnqueueTemplate(ctx context.Context, tx *sql.Tx, email string, name string, locale enums.Locale,
+	template enums.EmailTemplate, data map[string]any) {
+	message, err := mailer.Render(template, locale, data)
+	if err != nil {
+		panic(err)
+	}
+
+	message.ToEmail = email
+	message.ToName = name
+
+	svc.Enqueue(ctx, tx, message)
+}
+
+// EnqueueMessage queues the short text message to the phone number, to be sent through the channel, in the
+// transaction of the caller. It is only sent once the transaction is committed.
+func (svc *Service) EnqueueMessage(ctx context.Context, tx *sql.Tx, channel enums.MessageChannel,
+	message *domain.Message) {
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	svc.rpo.Create(ctx, tx, &domain.OutboxMessage{
+		Id:            id,
+		Channel:       channel,
+		Message:       *message,
+		Status:        enums.OUTBOX_PENDING,
+		NextAttemptAt: time.Now(),
+	})
+}
+
+// send delivers the message through the Messenger when it has a channel, and through the Mailer otherwise.
+func (svc *Service) send(ctx context.Context, message *domain.OutboxMessage) error {
+	if message.Channel != "" {
+		return svc.messenger.Send(ctx, message.Channel, &message.Message)
+	}
+
+	return svc.mail.Send(ctx, &message.Email)
+}
+
+// Dispatch delivers a batch of the emails that are due.
+//
+// The batch is claimed in a short transaction, and the emails are then sent outside of any transaction, so that
+// a slow Mailer never holds locks on the outbox. The outcome of every delivery is recorded in its own transaction.
+func (svc *Service) Dispatch(ctx context.Context) {
+	log := config.CreateLoggers(nil)
+
+	for _, message := range svc.claimDue(ctx) {
+		errSend := svc.send(ctx, message)
+
+		now := time.Now()
+
+		if errSend == nil {
+			message.Status = enums.OUTBOX_SENT
+			message.SentAt = sql.NullTime{Time: now, Valid: true}
+			message.LastError = ""
+
+			clearBody(message)
+		} else {
+			message.Attempts++
+			message.LastError = errSend.Error()
+
+			if message.Attempts >= maxAttempts() {
+				message.Status = enums.OUTBOX_DEAD
+
+				log.Error(fmt.Sprintf("outbox message %s dead after %d attempts: %v", message.Id, message.Attempts,
+					errSend))
+			} else {
+				message.NextAttemptAt = now.Add(backoff(message.Attempts))
+			}
+		}
+
+		svc.update(ctx, message)
+	}
+}
+
+// claimDue claims a batch of the emails that are due, postponing them by claimTimeout so that the other
+// dispatchers skip them while they are sent. The emails to a suppressed address are marked SUPPRESSED instead.
+func (svc *Service) claimDue(ctx context.Context) []*domain.OutboxMessage {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	now := time.Now()
+
+	var claimed []*domain.OutboxMessage
+	for _, message := range svc.rpo.ClaimDue(ctx, tx, now, batchSize()) {
+		if message.Channel == "" {
+			svc.suppress(ctx, tx, message)
+		}
+
+		if message.Status == enums.OUTBOX_SUPPRESSED {
+			continue
+		}
+
+		message.NextAttemptAt = now.Add(claimTimeout())
+
+		claimed = append(claimed, svc.rpo.Update(ctx, tx, message))
+	}
+
+	return claimed
+}
+
+// suppress marks an email to an address of the suppression list SUPPRESSED.
+func (svc *Service) suppress(ctx context.Context, tx *sql.Tx, message *domain.OutboxMessage) {
+	suppression, errSuppression := svc.srpo.Find(ctx, tx, message.Email.ToEmail)
+	if errSuppression == nil {
+		message.Status = enums.OUTBOX_SUPPRESSED
+		message.LastError = "email suppressed: " + string(suppression.Reason)
+
+		clearBody(message)
+		svc.rpo.Update(ctx, tx, message)
+	}
+}
+
+// update records the outcome of the delivery of an email in its own transaction.
+func (svc *Service) update(ctx context.Context, message *domain.OutboxMessage) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	svc.rpo.Update(ctx, tx, message)
+}
+
+// PurgeMessages deletes the emails that are done with, SENT, SUPPRESSED or DEAD, once they are older than the
+// retention period.
+func (svc *Service) PurgeMessages(ctx context.Context) {
+	log := config.CreateLoggers(nil)
+
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	purged := svc.rpo.DeleteFinishedBefore(ctx, tx, time.Now().Add(-retention()))
+
+	log.Info(fmt.Sprintf("%d outbox messages purged", purged))
+}
+
+// GetMessages returns the latest emails with the status of the request, DEAD unless another one is requested.
+func (svc *Service) GetMessages(ctx context.Context, request *domain.OutboxMessagesRequest) []domain.OutboxMessageResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	status := request.Status
+	if status == "" {
+		status = enums.OUTBOX_DEAD
+	}
+
+	messages := svc.rpo.FindAllByStatus(ctx, tx, status, 100)
+
+	responses := make([]domain.OutboxMessageResponse, 0, len(messages))
+	for _, message := range messages {
+		responses = append(responses, messageResponse(message))
+	}
+
+	return responses
+}
+
+// RetryMessage queues an email that has not been sent again, for the dispatcher to deliver it right away with
+// a fresh count of attempts. A SUPPRESSED email cannot be retried, as its content has been dropped.
+func (svc *Service) RetryMessage(ctx context.Context, id string) domain.OutboxMessageResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	message, errMessage := svc.rpo.FindById(ctx, tx, id)
+	if errMessage != nil {
+		panic(exceptions.NewNotFoundError(errMessage.Error()))
+	}
+
+	if message.Status == enums.OUTBOX_SENT {
+		panic(exceptions.NewDuplicateError("outbox message already sent"))
+	}
+
+	if message.Status == enums.OUTBOX_SUPPRESSED {
+		panic(exceptions.NewGoneError("outbox message suppressed"))
+	}
+
+	message.Status = enums.OUTBOX_PENDING
+	message.Attempts = 0
+	message.NextAttemptAt = time.Now()
+
+	message = svc.rpo.Update(ctx, tx, message)
+
+	return messageResponse(message)
+}
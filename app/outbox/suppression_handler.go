@@ -0,0 +1,93 @@
+package outbox
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"go-edash/domain"
+	"go-edash/response"
+	"io"
+	"net/http"
+)
+
+type SuppressionHandler struct {
+	svc domain.EmailSuppressionService
+}
+
+// MailjetWebhook is an HTTP handler function receiving the callbacks of the Event API of Mailjet.
+// The raw body and the basic auth credentials are handed to the service, which verifies them.
+// The response status code is set to 200 OK, which acknowledges the callback.
+func (hdl *SuppressionHandler) MailjetWebhook() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(request.Body, 1<<20))
+		if err != nil {
+			panic(err)
+		}
+
+		username, password, _ := request.BasicAuth()
+
+		ctx := request.Context()
+		hdl.svc.HandleMailjetEvents(ctx, username, password, body)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// GetSuppressions is an HTTP handler function that returns the latest suppressed emails.
+// The response status code is set to 200 OK.
+func (hdl *SuppressionHandler) GetSuppressions() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.GetSuppressions(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// DeleteSuppression is an HTTP handler function that removes the email named by the email URL parameter from the
+// suppression list.
+// The response status code is set to 200 OK.
+func (hdl *SuppressionHandler) DeleteSuppression() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		hdl.svc.DeleteSuppression(ctx, chi.URLParam(request, "email"))
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+// SuppressionRepository stores the suppression list of the emails. Like the outbox, it belongs to the
+// application, not to a company.
+type SuppressionRepository struct {
+}
+
+func scanSuppressions(rows *sql.Rows) []*domain.EmailSuppression {
+	defer rows.Close()
+
+	var suppressions []*domain.EmailSuppression
+	for rows.Next() {
+		suppression := new(domain.EmailSuppression)
+
+		err := rows.Scan(&suppression.Email, &suppression.Reason, &suppression.Detail, &suppression.CreatedAt)
+		if err != nil {
+			panic(err)
+		}
+
+		suppressions = append(suppressions, suppression)
+	}
+
+	return suppressions
+}
+
+// Save suppresses the email, or replaces the reason of an email already suppressed with the latest one.
+func (rpo *SuppressionRepository) Save(ctx context.Context, tx *sql.Tx,
+	suppression *domain.EmailSuppression) *domain.EmailSuppression {
+	query := `insert into email_suppressions (email,reason,detail,created_at) values (?,?,?,?)
+	on duplicate key update reason = values(reason), detail = values(detail)`
+
+	_, err := tx.ExecContext(ctx, query, suppression.Email, suppression.Reason, suppression.Detail,
+		suppression.CreatedAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return suppression
+}
+
+func (rpo *SuppressionRepository) Find(ctx context.Context, tx *sql.Tx, email string) (*domain.EmailSuppression, error) {
+	query := "select email, reason, detail, created_at from email_suppressions where email = ?"
+
+	rows, err := tx.QueryContext(ctx, query, email)
+	if err != nil {
+		panic(err)
+	}
+
+	suppressions := scanSuppressions(rows)
+	if len(suppressions) == 0 {
+		return new(domain.EmailSuppression), errors.New("email suppression not found")
+	}
+
+	return suppressions[0], nil
+}
+
+// FindAll returns the latest suppressed emails, up to the limit.
+func (rpo *SuppressionRepository) FindAll(ctx context.Context, tx *sql.Tx, limit int) []*domain.EmailSuppression {
+	query := "select email, reason, detail, created_at from email_suppressions order by created_at desc limit ?"
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		panic(err)
+	}
+
+	return scanSuppressions(rows)
+}
+
+// Delete removes the email from the suppression list. It returns false when the email was not suppressed.
+func (rpo *SuppressionRepository) Delete(ctx context.Context, tx *sql.Tx, email string) bool {
+	query := "delete from email_suppressions where email = ?"
+
+	result, err := tx.ExecContext(ctx, query, email)
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+
+	return affected > 0
+}
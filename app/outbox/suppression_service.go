@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/exceptions"
+	"go-edash/mailer"
+	"go-edash/utils"
+)
+
+// SuppressionService keeps the suppression list of the emails from the delivery events of the email provider.
+//
+// An address is suppressed when it bounced hard, when its emails were blocked because of it or when its recipient
+// marked an email as spam. The dispatcher of the outbox never sends an email to a suppressed address, which
+// protects the reputation of the sender. An operator removes an address from the list once it can receive emails
+// again.
+type SuppressionService struct {
+	rpo domain.EmailSuppressionRepository
+	db  *sql.DB
+}
+
+func suppressionResponse(suppression *domain.EmailSuppression) domain.EmailSuppressionResponse {
+	return domain.EmailSuppressionResponse{
+		Email:     suppression.Email,
+		Reason:    suppression.Reason,
+		Detail:    suppression.Detail,
+		CreatedAt: suppression.CreatedAt,
+	}
+}
+
+// HandleMailjetEvents processes a callback of the Event API of Mailjet.
+// Callbacks whose credentials do not verify are rejected. Mailjet sends the same callback again until it is
+// acknowledged, and suppressing an address again only refreshes its reason.
+func (svc *SuppressionService) HandleMailjetEvents(ctx context.Context, username string, password string, body []byte) {
+	events, errParse := mailer.ParseMailjetEvents(username, password, body)
+	if errParse != nil {
+		panic(exceptions.NewUnauthorizedError("invalid mailjet event: " + errParse.Error()))
+	}
+
+	log := config.CreateLoggers(nil)
+
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	for _, event := range events {
+		svc.rpo.Save(ctx, tx, &domain.EmailSuppression{
+			Email:     event.Email,
+			Reason:    event.Status,
+			Detail:    event.Detail,
+			CreatedAt: event.At,
+		})
+
+		log.Warn(fmt.Sprintf("email %s suppressed: %s (%s)", event.Email, event.Status, event.Detail))
+	}
+}
+
+// GetSuppressions returns the latest suppressed emails.
+func (svc *SuppressionService) GetSuppressions(ctx context.Context) []domain.EmailSuppressionResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	suppressions := svc.rpo.FindAll(ctx, tx, 100)
+
+	responses := make([]domain.EmailSuppressionResponse, 0, len(suppressions))
+	for _, suppression := range suppressions {
+		responses = append(responses, suppressionResponse(suppression))
+	}
+
+	return responses
+}
+
+// DeleteSuppression removes the email from the suppression list, so that the emails to it are sent again.
+// The emails that were suppressed meanwhile can be queued again with RetryMessage.
+func (svc *SuppressionService) DeleteSuppression(ctx context.Context, email string) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	if !svc.rpo.Delete(ctx, tx, email) {
+		panic(exceptions.NewNotFoundError("email suppression not found"))
+	}
+}
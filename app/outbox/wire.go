@@ -0,0 +1,19 @@
+//go:build wireinject
+// +build wireinject
+
+package outbox
+
+import (
+	"database/sql"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+	"go-edash/domain"
+)
+
+func Wire(validate *validator.Validate, db *sql.DB, mail domain.Mailer, messenger domain.Messenger) *Router {
+	panic(wire.Build(ProviderSet))
+}
+
+func WireService(db *sql.DB, mail domain.Mailer, messenger domain.Messenger) *Service {
+	panic(wire.Build(ServiceSet))
+}
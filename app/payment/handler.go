@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"go-edash/domain"
+	"go-edash/response"
+	"io"
+	"net/http"
+)
+
+type Handler struct {
+	svc      domain.PaymentService
+	validate *validator.Validate
+}
+
+// Checkout is an HTTP handler function that creates a charge for a plan.
+// It expects a JSON payload in the request body that conforms to the CheckoutRequest struct.
+// It returns a JSON response with the URL where the charge is paid and the status code set to 201 Created.
+func (hdl *Handler) Checkout() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.CheckoutRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.Checkout(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusCreated,
+			Status: http.StatusText(http.StatusCreated),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Webhook is an HTTP handler function receiving the callbacks of the payment provider.
+// The raw body is handed to the service, which verifies its signature.
+// The response status code is set to 200 OK, which acknowledges the callback.
+func (hdl *Handler) Webhook() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(request.Body, 1<<20))
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		hdl.svc.HandleNotification(ctx, body)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
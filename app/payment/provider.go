@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"database/sql"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+	"go-edash/app/company"
+	"go-edash/app/subscription"
+	"go-edash/domain"
+	"sync"
+)
+
+var (
+	route     *Router
+	routeOnce sync.Once
+
+	hdl     *Handler
+	hdlOnce sync.Once
+
+	svc     *Service
+	svcOnce sync.Once
+
+	rpo     *Repository
+	rpoOnce sync.Once
+
+	ProviderSet = wire.NewSet(
+		company.InvoiceSet,
+		subscription.EntitlementSet,
+		ProvideRouter,
+		ProvideHandler,
+		ProvideService,
+		ProvideRepository,
+		wire.Bind(new(domain.PaymentHandler), new(*Handler)),
+		wire.Bind(new(domain.PaymentService), new(*Service)),
+		wire.Bind(new(domain.PaymentRepository), new(*Repository)),
+	)
+)
+
+func ProvideRouter(hdl domain.PaymentHandler) *Router {
+	routeOnce.Do(func() {
+		route = &Router{
+			hdl: hdl,
+		}
+	})
+
+	return route
+}
+
+func ProvideHandler(validate *validator.Validate, svc domain.PaymentService) *Handler {
+	hdlOnce.Do(func() {
+		hdl = &Handler{
+			svc:      svc,
+			validate: validate,
+		}
+	})
+
+	return hdl
+}
+
+func ProvideService(rpo domain.PaymentRepository, prpo domain.PlanRepository, srpo domain.SubscriptionRepository,
+	isvc domain.InvoiceService, db *sql.DB, provider domain.PaymentProvider) *Service {
+	svcOnce.Do(func() {
+		svc = &Service{
+			rpo:      rpo,
+			prpo:     prpo,
+			srpo:     srpo,
+			isvc:     isvc,
+			db:       db,
+			provider: provider,
+		}
+	})
+
+	return svc
+}
+
+func ProvideRepository() *Repository {
+	rpoOnce.Do(func() {
+		rpo = new(Repository)
+	})
+
+	return rpo
+}
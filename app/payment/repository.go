@@ -0,0 +1,93 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+// Repository stores the payments of the company the context is scoped to.
+// FindById is the exception, since the callbacks of the payment provider only know the id of a payment.
+type Repository struct {
+}
+
+// Create records a payment of the company the context is scoped to.
+func (rpo *Repository) Create(ctx context.Context, tx *sql.Tx, payment *domain.Payment) *domain.Payment {
+	payment.CompanyId = domain.MustTenant(ctx)
+
+	query := "insert into payments (id,company_id,plan_id,amount,status) values (?,?,?,?,?)"
+
+	_, err := tx.ExecContext(ctx, query, payment.Id, payment.CompanyId, payment.PlanId, payment.Amount, payment.Status)
+	if err != nil {
+		panic(err)
+	}
+
+	return payment
+}
+
+func (rpo *Repository) Update(ctx context.Context, tx *sql.Tx, payment *domain.Payment) *domain.Payment {
+	query := "update payments set status=?,reference=?,redirect_url=?,paid_at=? where id = ? and company_id = ?"
+
+	result, err := tx.ExecContext(ctx, query, payment.Status, payment.Reference, payment.RedirectUrl, payment.PaidAt,
+		payment.Id, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	domain.MustAffectTenant(result, "payment")
+
+	return payment
+}
+
+// FindById returns the payment whatever company it belongs to, and locks it until the transaction ends.
+// The caller scopes the context to the company of the payment before working with it. The payments of a purged
+// company have no company.
+func (rpo *Repository) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.Payment, error) {
+	query := `select id, company_id, plan_id, amount, status, reference, redirect_url, paid_at from payments
+	where id = ? for update`
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	payment := new(domain.Payment)
+	if rows.Next() {
+		var companyId, reference, redirectUrl sql.NullString
+
+		err = rows.Scan(&payment.Id, &companyId, &payment.PlanId, &payment.Amount, &payment.Status,
+			&reference, &redirectUrl, &payment.PaidAt)
+		if err != nil {
+			panic(err)
+		}
+
+		payment.CompanyId = companyId.String
+		payment.Reference = reference.String
+		payment.RedirectUrl = redirectUrl.String
+
+		return payment, nil
+	} else {
+		return payment, errors.New("payment not found")
+	}
+}
+
+// RecordNotification records that a notification has been processed.
+// It returns false when it had already been, which is how notifications sent again by the provider are ignored.
+func (rpo *Repository) RecordNotification(ctx context.Context, tx *sql.Tx, key string) bool {
+	query := "insert ignore into payment_notifications (notification_key) values (?)"
+
+	result, err := tx.ExecContext(ctx, query, key)
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+
+	return affected == 1
+}
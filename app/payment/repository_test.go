@@ -0,0 +1,50 @@
+package payment
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"regexp"
+	"testing"
+)
+
+func TestUpdateRejectsThePaymentOfAnotherCompany(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	mock.ExpectBegin()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := domain.NewTenantContext(context.Background(), "company-a")
+
+	// The payment of company B is not found in company A, so nothing is updated
+	query := "update payments set status=?,reference=?,redirect_url=?,paid_at=? where id = ? and company_id = ?"
+
+	mock.ExpectExec(regexp.QuoteMeta(query)).
+		WithArgs(enums.PAYMENT_PAID, "", "", sqlmock.AnyArg(), "payment-b", "company-a").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	func() {
+		defer func() {
+			if _, ok := recover().(exceptions.NotFoundError); !ok {
+				t.Fatal("expected a NotFoundError")
+			}
+		}()
+
+		new(Repository).Update(ctx, tx, &domain.Payment{Id: "payment-b", Status: enums.PAYMENT_PAID})
+	}()
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,25 @@
+package payment
+
+import (
+	"github.com/go-chi/chi/v5"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/middlewares"
+)
+
+type Router struct {
+	hdl domain.PaymentHandler
+}
+
+func (router *Router) InitializeRoute(rtr *chi.Mux) {
+	rtr.Route("/api/payment", func(route chi.Router) {
+		route.Post("/webhook", router.hdl.Webhook())
+
+		route.Group(func(secure chi.Router) {
+			secure.Use(middlewares.AuthorizationCheckMiddleware)
+			secure.Use(middlewares.VerifyTokenMiddleware)
+			secure.With(middlewares.RequireOnboardingStep(enums.COMPANY_CREATED),
+				middlewares.RequirePermission(enums.SUBSCRIPTION_MANAGE)).Post("/checkout", router.hdl.Checkout())
+		})
+	})
+}
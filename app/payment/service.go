@@ -0,0 +1,221 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"go-edash/utils"
+	"time"
+)
+
+type Service struct {
+	rpo      domain.PaymentRepository
+	prpo     domain.PlanRepository
+	srpo     domain.SubscriptionRepository
+	isvc     domain.InvoiceService
+	db       *sql.DB
+	provider domain.PaymentProvider
+}
+
+// periodMonths returns the number of months a payment subscribes to a plan for.
+// It is read from SUBSCRIPTION_PERIOD_MONTHS and defaults to 1.
+func periodMonths() int {
+	months := viper.GetInt("SUBSCRIPTION_PERIOD_MONTHS")
+	if months <= 0 {
+		return 1
+	}
+
+	return months
+}
+
+// Checkout creates a charge of the payment provider for a plan, paid for the company of the authenticated user.
+// It returns the URL where the user pays the charge. The subscription is only activated once the provider
+// notifies that the charge has been paid.
+//
+// The PENDING payment is committed before the provider is called, so that no transaction stays open during the
+// call and a notification for the charge always finds its payment. The charge is then recorded in a second
+// transaction, or the payment is marked FAILED when the provider refuses it. When the call fails otherwise, the
+// charge may have been created anyway, so the payment stays PENDING for its notification to settle it.
+func (svc *Service) Checkout(ctx context.Context, request *domain.CheckoutRequest) domain.CheckoutResponse {
+	principal := domain.MustPrincipal(ctx)
+
+	payment, plan := svc.createPayment(ctx, request)
+
+	checkout, errCharge := svc.provider.CreateCharge(ctx, &domain.PaymentCharge{
+		OrderId:       payment.Id,
+		Amount:        payment.Amount,
+		ItemName:      "EDash " + plan.Name,
+		CustomerName:  principal.Email,
+		CustomerEmail: principal.Email,
+	})
+	if errCharge != nil {
+		var rejected domain.PaymentRejectedError
+		if errors.As(errCharge, &rejected) {
+			payment.Status = enums.PAYMENT_FAILED
+			svc.updatePayment(ctx, payment)
+		}
+
+		panic(errCharge)
+	}
+
+	payment.Reference = checkout.Reference
+	payment.RedirectUrl = checkout.RedirectUrl
+
+	svc.updatePayment(ctx, payment)
+
+	return domain.CheckoutResponse{
+		OrderId:     payment.Id,
+		Amount:      payment.Amount,
+		RedirectUrl: payment.RedirectUrl,
+	}
+}
+
+// createPayment creates the PENDING payment of a checkout in its own transaction.
+func (svc *Service) createPayment(ctx context.Context, request *domain.CheckoutRequest) (*domain.Payment, *domain.Plan) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	plan, errPlan := svc.prpo.FindByCode(ctx, tx, request.PlanCode)
+	if errPlan != nil || plan.Price <= 0 {
+		panic(exceptions.NewNotFoundError("plan not found"))
+	}
+
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	payment := svc.rpo.Create(ctx, tx, &domain.Payment{
+		Id:     id,
+		PlanId: plan.Id,
+		Amount: plan.Price,
+		Status: enums.PAYMENT_PENDING,
+	})
+
+	return payment, plan
+}
+
+// updatePayment saves the payment of a checkout in its own transaction, unless a notification of the provider has
+// settled it in the meantime.
+func (svc *Service) updatePayment(ctx context.Context, payment *domain.Payment) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	current, errFind := svc.rpo.FindById(ctx, tx, payment.Id)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	if current.Status != enums.PAYMENT_PENDING {
+		return
+	}
+
+	svc.rpo.Update(ctx, tx, payment)
+}
+
+// HandleNotification processes a callback of the payment provider.
+//
+// Callbacks whose signature does not verify are rejected. Every callback is only processed once, and a payment
+// is only settled once, since providers send the same callback again until it is acknowledged. A paid charge
+// activates the subscription of the company and issues its invoice, even when its payment has been marked FAILED,
+// as the customer has been charged anyway.
+func (svc *Service) HandleNotification(ctx context.Context, body []byte) {
+	notification, errParse := svc.provider.ParseNotification(body)
+	if errParse != nil {
+		panic(exceptions.NewUnauthorizedError("invalid payment notification: " + errParse.Error()))
+	}
+
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	if !svc.rpo.RecordNotification(ctx, tx, notification.TransactionId+":"+string(notification.Status)) {
+		return
+	}
+
+	payment, errFind := svc.rpo.FindById(ctx, tx, notification.OrderId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	// The payments of a purged company are only kept as records
+	if payment.CompanyId == "" || payment.Status == enums.PAYMENT_PAID {
+		return
+	}
+
+	if payment.Status == enums.PAYMENT_FAILED && notification.Status != enums.PAYMENT_PAID {
+		return
+	}
+
+	ctx = domain.NewTenantContext(ctx, payment.CompanyId)
+
+	switch notification.Status {
+	case enums.PAYMENT_PAID:
+		if notification.Amount != payment.Amount {
+			panic(exceptions.NewNotMatchedError("amount not matched"))
+		}
+
+		payment.Status = enums.PAYMENT_PAID
+		payment.PaidAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+		plan, errPlan := svc.prpo.FindById(ctx, tx, payment.PlanId)
+		if errPlan != nil {
+			panic(exceptions.NewNotFoundError(errPlan.Error()))
+		}
+
+		svc.rpo.Update(ctx, tx, payment)
+		subscription := svc.activateSubscription(ctx, tx, payment)
+		svc.isvc.Issue(ctx, tx, payment, plan, subscription)
+	case enums.PAYMENT_FAILED:
+		payment.Status = enums.PAYMENT_FAILED
+
+		svc.rpo.Update(ctx, tx, payment)
+	}
+}
+
+// activateSubscription subscribes the company of a paid payment to its plan.
+//
+// Paying for the plan the company is already subscribed to adds a period after the last one. Paying for another
+// plan replaces the subscriptions of the company right away.
+func (svc *Service) activateSubscription(ctx context.Context, tx *sql.Tx,
+	payment *domain.Payment) *domain.Subscription {
+	now := time.Now()
+	start := now
+
+	latest, errLatest := svc.srpo.FindLatest(ctx, tx, now)
+	if errLatest == nil {
+		if latest.PlanId == payment.PlanId {
+			start = latest.PeriodEnd
+		} else {
+			svc.srpo.Expire(ctx, tx)
+		}
+	}
+
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	return svc.srpo.Create(ctx, tx, &domain.Subscription{
+		Id:          id,
+		PlanId:      payment.PlanId,
+		Status:      enums.SUBSCRIPTION_ACTIVE,
+		PeriodStart: start,
+		PeriodEnd:   start.AddDate(0, periodMonths(), 0),
+	})
+}
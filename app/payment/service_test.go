@@ -0,0 +1,448 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/middlewares"
+	paymentProvider "go-edash/payment"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// paymentStore keeps the payments of the test in memory, in place of the payments table.
+type paymentStore struct {
+	mutex         sync.Mutex
+	payments      map[string]domain.Payment
+	notifications map[string]bool
+}
+
+func (store *paymentStore) Create(ctx context.Context, tx *sql.Tx, payment *domain.Payment) *domain.Payment {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	payment.CompanyId = domain.MustTenant(ctx)
+	store.payments[payment.Id] = *payment
+
+	return payment
+}
+
+func (store *paymentStore) Update(ctx context.Context, tx *sql.Tx, payment *domain.Payment) *domain.Payment {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.payments[payment.Id].CompanyId != domain.MustTenant(ctx) {
+		panic("payment of another company")
+	}
+
+	store.payments[payment.Id] = *payment
+
+	return payment
+}
+
+func (store *paymentStore) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.Payment, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	payment, ok := store.payments[id]
+	if !ok {
+		return new(domain.Payment), errors.New("payment not found")
+	}
+
+	return &payment, nil
+}
+
+func (store *paymentStore) RecordNotification(ctx context.Context, tx *sql.Tx, key string) bool {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.notifications[key] {
+		return false
+	}
+
+	store.notifications[key] = true
+
+	return true
+}
+
+// planStore serves a single plan.
+type planStore struct {
+	plan *domain.Plan
+}
+
+func (store *planStore) FindAll(ctx context.Context, tx *sql.Tx) []*domain.Plan {
+	return []*domain.Plan{store.plan}
+}
+
+func (store *planStore) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.Plan, error) {
+	if id != store.plan.Id {
+		return new(domain.Plan), errors.New("plan not found")
+	}
+
+	return store.plan, nil
+}
+
+func (store *planStore) FindByCode(ctx context.Context, tx *sql.Tx, code string) (*domain.Plan, error) {
+	if code != store.plan.Code {
+		return new(domain.Plan), errors.New("plan not found")
+	}
+
+	return store.plan, nil
+}
+
+// subscriptionStore records the subscriptions created by the test.
+type subscriptionStore struct {
+	subscriptions []*domain.Subscription
+}
+
+func (store *subscriptionStore) Create(ctx context.Context, tx *sql.Tx, subscription *domain.Subscription) *domain.Subscription {
+	subscription.CompanyId = domain.MustTenant(ctx)
+	store.subscriptions = append(store.subscriptions, subscription)
+
+	return subscription
+}
+
+func (store *subscriptionStore) Expire(ctx context.Context, tx *sql.Tx) {}
+
+func (store *subscriptionStore) FindCurrent(ctx context.Context, tx *sql.Tx, at time.Time) (*domain.Subscription, error) {
+	return store.FindLatest(ctx, tx, at)
+}
+
+func (store *subscriptionStore) FindLatest(ctx context.Context, tx *sql.Tx, at time.Time) (*domain.Subscription, error) {
+	if len(store.subscriptions) == 0 {
+		return new(domain.Subscription), errors.New("subscription not found")
+	}
+
+	return store.subscriptions[len(store.subscriptions)-1], nil
+}
+
+func (store *subscriptionStore) FindTrialStart(ctx context.Context, tx *sql.Tx) (time.Time, error) {
+	return time.Time{}, errors.New("trial not found")
+}
+
+func (store *subscriptionStore) CountMembers(ctx context.Context, tx *sql.Tx) int64 {
+	return 1
+}
+
+// invoiceIssuer counts the invoices issued by the test.
+type invoiceIssuer struct {
+	issued int
+}
+
+func (issuer *invoiceIssuer) Issue(ctx context.Context, tx *sql.Tx, payment *domain.Payment, plan *domain.Plan,
+	subscription *domain.Subscription) *domain.Invoice {
+	issuer.issued++
+
+	return new(domain.Invoice)
+}
+
+func (issuer *invoiceIssuer) GetInvoices(ctx context.Context) []domain.InvoiceResponse {
+	return nil
+}
+
+func (issuer *invoiceIssuer) DownloadInvoice(ctx context.Context, id string) domain.InvoiceDocument {
+	return domain.InvoiceDocument{}
+}
+
+// TestCheckoutWithFakeServer pays a checkout through the fake payment server, whose notification is delivered to
+// the webhook of the application.
+func TestCheckoutWithFakeServer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	payments := &paymentStore{payments: map[string]domain.Payment{}, notifications: map[string]bool{}}
+	subscriptions := new(subscriptionStore)
+	invoices := new(invoiceIssuer)
+	plan := &domain.Plan{Id: "plan-pro", Code: "PRO", Name: "Pro", Price: 150000}
+
+	// Record the notifications delivered to the webhook, to replay and tamper with them
+	var mutex sync.Mutex
+	var notifications [][]byte
+
+	var service *Service
+	webhook := httptest.NewServer(middlewares.RecoverMiddleware(http.HandlerFunc(
+		func(writer http.ResponseWriter, request *http.Request) {
+			body, _ := io.ReadAll(request.Body)
+
+			mutex.Lock()
+			notifications = append(notifications, body)
+			mutex.Unlock()
+
+			request.Body = io.NopCloser(bytes.NewReader(body))
+			(&Handler{svc: service}).Webhook()(writer, request)
+		})))
+	defer webhook.Close()
+
+	viper.Set("APP_ENV", "development")
+	viper.Set("PAYMENT_PROVIDER", "fake")
+	viper.Set("PAYMENT_FAKE_WEBHOOK_URL", webhook.URL)
+	defer viper.Set("APP_ENV", "")
+	defer viper.Set("PAYMENT_PROVIDER", "")
+	defer viper.Set("PAYMENT_FAKE_WEBHOOK_URL", "")
+
+	provider, err := paymentProvider.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service = &Service{
+		rpo:      payments,
+		prpo:     &planStore{plan: plan},
+		srpo:     subscriptions,
+		isvc:     invoices,
+		db:       db,
+		provider: provider,
+	}
+
+	ctx := domain.NewPrincipalContext(context.Background(), &domain.Principal{
+		UserId:    "user-a",
+		Email:     "owner@example.com",
+		CompanyId: "company-a",
+	})
+	ctx = domain.NewTenantContext(ctx, "company-a")
+
+	// The pending payment and the charge are recorded in two transactions
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	checkout := service.Checkout(ctx, &domain.CheckoutRequest{PlanCode: "PRO"})
+
+	if checkout.Amount != plan.Price || checkout.RedirectUrl == "" {
+		t.Fatalf("unexpected checkout %+v", checkout)
+	}
+
+	if payment := payments.payments[checkout.OrderId]; payment.Status != enums.PAYMENT_PENDING || payment.Reference == "" {
+		t.Fatalf("expected a pending payment with a reference, got %+v", payment)
+	}
+
+	// Paying the charge sends the notification settling the payment
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	response, err := http.Get(checkout.RedirectUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected the notification to be acknowledged, got %d", response.StatusCode)
+	}
+
+	payment := payments.payments[checkout.OrderId]
+	if payment.Status != enums.PAYMENT_PAID || !payment.PaidAt.Valid {
+		t.Fatalf("expected the payment to be paid, got %+v", payment)
+	}
+
+	if len(subscriptions.subscriptions) != 1 || invoices.issued != 1 {
+		t.Fatalf("expected one subscription and one invoice, got %d and %d", len(subscriptions.subscriptions),
+			invoices.issued)
+	}
+
+	subscription := subscriptions.subscriptions[0]
+	if subscription.CompanyId != "company-a" || subscription.PlanId != plan.Id ||
+		subscription.Status != enums.SUBSCRIPTION_ACTIVE {
+		t.Fatalf("unexpected subscription %+v", subscription)
+	}
+
+	if len(notifications) != 1 {
+		t.Fatalf("expected one notification, got %d", len(notifications))
+	}
+
+	// A notification sent again is acknowledged without settling the payment twice
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	response, err = http.Post(webhook.URL, "application/json", bytes.NewReader(notifications[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected the replayed notification to be acknowledged, got %d", response.StatusCode)
+	}
+
+	if len(subscriptions.subscriptions) != 1 || invoices.issued != 1 {
+		t.Fatalf("expected the replayed notification to be ignored, got %d subscriptions and %d invoices",
+			len(subscriptions.subscriptions), invoices.issued)
+	}
+
+	// A notification whose signature does not verify is rejected before any transaction
+	tampered := map[string]any{}
+
+	err = json.Unmarshal(notifications[0], &tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered["transaction_id"] = "forged"
+	tampered["signature_key"] = "forged"
+
+	body, err := json.Marshal(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err = http.Post(webhook.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = response.Body.Close()
+
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the forged notification to be rejected, got %d", response.StatusCode)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// failingProvider fails to create the charges with its error, and trusts the notifications it is given.
+type failingProvider struct {
+	err error
+}
+
+func (provider *failingProvider) CreateCharge(ctx context.Context,
+	charge *domain.PaymentCharge) (*domain.PaymentCheckout, error) {
+	return nil, provider.err
+}
+
+func (provider *failingProvider) ParseNotification(body []byte) (*domain.PaymentNotification, error) {
+	notification := new(domain.PaymentNotification)
+
+	err := json.Unmarshal(body, notification)
+	if err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// TestCheckoutFailureSettledByNotification checks that a charge whose creation failed is still settled by the
+// notification of its payment, whether the provider timed out or refused the charge.
+func TestCheckoutFailureSettledByNotification(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected enums.PaymentStatus
+	}{
+		{name: "timeout", err: context.DeadlineExceeded, expected: enums.PAYMENT_PENDING},
+		{name: "server error", err: errors.New("midtrans failed to create the transaction with status 502"),
+			expected: enums.PAYMENT_PENDING},
+		{name: "rejected", err: domain.PaymentRejectedError{Message: "midtrans rejected the transaction"},
+			expected: enums.PAYMENT_FAILED},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer db.Close()
+
+			payments := &paymentStore{payments: map[string]domain.Payment{}, notifications: map[string]bool{}}
+			subscriptions := new(subscriptionStore)
+			invoices := new(invoiceIssuer)
+			plan := &domain.Plan{Id: "plan-pro", Code: "PRO", Name: "Pro", Price: 150000}
+
+			service := &Service{
+				rpo:      payments,
+				prpo:     &planStore{plan: plan},
+				srpo:     subscriptions,
+				isvc:     invoices,
+				db:       db,
+				provider: &failingProvider{err: test.err},
+			}
+
+			ctx := domain.NewPrincipalContext(context.Background(), &domain.Principal{
+				UserId:    "user-a",
+				Email:     "owner@example.com",
+				CompanyId: "company-a",
+			})
+			ctx = domain.NewTenantContext(ctx, "company-a")
+
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			if test.expected == enums.PAYMENT_FAILED {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			}
+
+			func() {
+				defer func() {
+					if recovered, _ := recover().(error); !errors.Is(recovered, test.err) {
+						t.Fatalf("expected the checkout to fail with %v, got %v", test.err, recovered)
+					}
+				}()
+
+				service.Checkout(ctx, &domain.CheckoutRequest{PlanCode: "PRO"})
+			}()
+
+			if len(payments.payments) != 1 {
+				t.Fatalf("expected one payment, got %d", len(payments.payments))
+			}
+
+			var payment domain.Payment
+			for _, created := range payments.payments {
+				payment = created
+			}
+
+			if payment.Status != test.expected {
+				t.Fatalf("expected the payment to be %s, got %s", test.expected, payment.Status)
+			}
+
+			// The customer has paid the charge created by the provider anyway
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			body, err := json.Marshal(&domain.PaymentNotification{
+				OrderId:       payment.Id,
+				TransactionId: "transaction-a",
+				Status:        enums.PAYMENT_PAID,
+				Amount:        plan.Price,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			service.HandleNotification(context.Background(), body)
+
+			if payment = payments.payments[payment.Id]; payment.Status != enums.PAYMENT_PAID {
+				t.Fatalf("expected the payment to be paid, got %s", payment.Status)
+			}
+
+			if len(subscriptions.subscriptions) != 1 || invoices.issued != 1 {
+				t.Fatalf("expected one subscription and one invoice, got %d and %d",
+					len(subscriptions.subscriptions), invoices.issued)
+			}
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
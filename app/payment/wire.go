@@ -0,0 +1,15 @@
+//go:build wireinject
+// +build wireinject
+
+package payment
+
+import (
+	"database/sql"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+	"go-edash/domain"
+)
+
+func Wire(validate *validator.Validate, db *sql.DB, provider domain.PaymentProvider) *Router {
+	panic(wire.Build(ProviderSet))
+}
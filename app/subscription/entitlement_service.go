@@ -0,0 +1,123 @@
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"go-edash/utils"
+	"strconv"
+	"time"
+)
+
+// EntitlementService resolves what a company may use.
+//
+// A company is entitled to the plan of its current subscription. Without one, it is entitled to the trial plan
+// (TRIAL_PLAN_CODE, defaults to "TRIAL") while the free trial of one of its members runs. Otherwise it is not
+// entitled to any paid feature.
+type EntitlementService struct {
+	prpo domain.PlanRepository
+	srpo domain.SubscriptionRepository
+	db   *sql.DB
+}
+
+func trialPlanCode() string {
+	code := viper.GetString("TRIAL_PLAN_CODE")
+	if code == "" {
+		return "TRIAL"
+	}
+
+	return code
+}
+
+// planLimit returns the limit of the plan on the resource, and false when the plan does not limit it.
+func planLimit(plan *domain.Plan, limit enums.PlanLimit) (int64, bool) {
+	var value sql.NullInt64
+
+	switch limit {
+	case enums.MEMBER_SEATS:
+		value = plan.MaxMembers
+	case enums.DASHBOARDS:
+		value = plan.MaxDashboards
+	case enums.API_CALLS:
+		value = plan.MaxApiCalls
+	}
+
+	return value.Int64, value.Valid
+}
+
+// Resolve returns the entitlements of the company, and false when it is not entitled to any paid feature.
+// A context that is not scoped to any company is not entitled to anything.
+func (svc *EntitlementService) Resolve(ctx context.Context, tx *sql.Tx) (*domain.Entitlements, bool) {
+	if _, ok := domain.TenantFromContext(ctx); !ok {
+		return nil, false
+	}
+
+	now := time.Now()
+
+	subscription, errSubscription := svc.srpo.FindCurrent(ctx, tx, now)
+	if errSubscription == nil {
+		plan, errPlan := svc.prpo.FindById(ctx, tx, subscription.PlanId)
+		if errPlan != nil {
+			panic(errPlan)
+		}
+
+		return &domain.Entitlements{Plan: plan, ActiveUntil: subscription.PeriodEnd}, true
+	}
+
+	trialStart, errTrial := svc.srpo.FindTrialStart(ctx, tx)
+	if errTrial != nil {
+		return nil, false
+	}
+
+	trialEnd := config.TrialEnd(trialStart)
+	if !now.Before(trialEnd) {
+		return nil, false
+	}
+
+	plan, errPlan := svc.prpo.FindByCode(ctx, tx, trialPlanCode())
+	if errPlan != nil {
+		panic(errPlan)
+	}
+
+	return &domain.Entitlements{Plan: plan, Trial: true, ActiveUntil: trialEnd}, true
+}
+
+// HasPaidAccess reports whether the company is entitled to the paid features.
+// It runs in its own transaction, so that middleware can call it.
+func (svc *EntitlementService) HasPaidAccess(ctx context.Context) bool {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	_, entitled := svc.Resolve(ctx, tx)
+
+	return entitled
+}
+
+// EnsureCapacity checks that the company may use one more unit of the resource, given how many it already uses.
+// It panics with a PaymentRequiredError when the company is not entitled to it or its plan limit is reached.
+func (svc *EntitlementService) EnsureCapacity(ctx context.Context, tx *sql.Tx, limit enums.PlanLimit, used int64) {
+	entitlements, entitled := svc.Resolve(ctx, tx)
+	if !entitled {
+		panic(exceptions.NewPaymentRequiredError("no active subscription"))
+	}
+
+	max, limited := planLimit(entitlements.Plan, limit)
+	if limited && used >= max {
+		panic(exceptions.NewPaymentRequiredError("plan " + entitlements.Plan.Code + " is limited to " +
+			strconv.FormatInt(max, 10) + " " + string(limit)))
+	}
+}
+
+// EnsureSeatAvailable checks that one more member can be added to the company.
+// It panics with a PaymentRequiredError when every seat of the plan is taken.
+func (svc *EntitlementService) EnsureSeatAvailable(ctx context.Context, tx *sql.Tx) {
+	svc.EnsureCapacity(ctx, tx, enums.MEMBER_SEATS, svc.srpo.CountMembers(ctx, tx))
+}
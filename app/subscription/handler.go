@@ -0,0 +1,60 @@
+package subscription
+
+import (
+	"encoding/json"
+	"go-edash/domain"
+	"go-edash/response"
+	"net/http"
+)
+
+type Handler struct {
+	svc domain.SubscriptionService
+}
+
+// GetPlans is an HTTP handler function that returns the plans catalog.
+// The response status code is set to 200 OK.
+func (hdl *Handler) GetPlans() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.GetPlans(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// GetSubscription is an HTTP handler function that returns the plan the company of the authenticated user
+// is entitled to. The response status code is set to 200 OK.
+func (hdl *Handler) GetSubscription() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.GetSubscription(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
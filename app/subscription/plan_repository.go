@@ -0,0 +1,67 @@
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+type PlanRepository struct {
+}
+
+// planColumns lists the columns read by scanPlan, in the order they are scanned.
+const planColumns = "id, code, name, price, max_members, max_dashboards, max_api_calls"
+
+func scanPlan(rows *sql.Rows) *domain.Plan {
+	plan := new(domain.Plan)
+
+	err := rows.Scan(&plan.Id, &plan.Code, &plan.Name, &plan.Price, &plan.MaxMembers, &plan.MaxDashboards,
+		&plan.MaxApiCalls)
+	if err != nil {
+		panic(err)
+	}
+
+	return plan
+}
+
+func (rpo *PlanRepository) FindAll(ctx context.Context, tx *sql.Tx) []*domain.Plan {
+	query := "select " + planColumns + " from plans order by price"
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var plans []*domain.Plan
+	for rows.Next() {
+		plans = append(plans, scanPlan(rows))
+	}
+
+	return plans
+}
+
+func (rpo *PlanRepository) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.Plan, error) {
+	return rpo.findOne(ctx, tx, "select "+planColumns+" from plans where id = ?", id)
+}
+
+func (rpo *PlanRepository) FindByCode(ctx context.Context, tx *sql.Tx, code string) (*domain.Plan, error) {
+	return rpo.findOne(ctx, tx, "select "+planColumns+" from plans where code = ?", code)
+}
+
+func (rpo *PlanRepository) findOne(ctx context.Context, tx *sql.Tx, query string, arg string) (*domain.Plan, error) {
+	rows, err := tx.QueryContext(ctx, query, arg)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanPlan(rows), nil
+	} else {
+		return new(domain.Plan), errors.New("plan not found")
+	}
+}
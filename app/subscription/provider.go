@@ -0,0 +1,109 @@
+package subscription
+
+import (
+	"database/sql"
+	"github.com/google/wire"
+	"go-edash/domain"
+	"sync"
+)
+
+var (
+	route     *Router
+	routeOnce sync.Once
+
+	hdl     *Handler
+	hdlOnce sync.Once
+
+	svc     *Service
+	svcOnce sync.Once
+
+	esvc     *EntitlementService
+	esvcOnce sync.Once
+
+	srpo     *Repository
+	srpoOnce sync.Once
+
+	prpo     *PlanRepository
+	prpoOnce sync.Once
+
+	// EntitlementSet provides the EntitlementService to the modules checking what a company may use.
+	EntitlementSet = wire.NewSet(
+		ProvideEntitlementService,
+		ProvideRepository,
+		ProvidePlanRepository,
+		wire.Bind(new(domain.EntitlementService), new(*EntitlementService)),
+		wire.Bind(new(domain.SubscriptionRepository), new(*Repository)),
+		wire.Bind(new(domain.PlanRepository), new(*PlanRepository)),
+	)
+
+	ProviderSet = wire.NewSet(
+		EntitlementSet,
+		ProvideRouter,
+		ProvideHandler,
+		ProvideService,
+		wire.Bind(new(domain.SubscriptionHandler), new(*Handler)),
+		wire.Bind(new(domain.SubscriptionService), new(*Service)),
+	)
+)
+
+func ProvideRouter(hdl domain.SubscriptionHandler) *Router {
+	routeOnce.Do(func() {
+		route = &Router{
+			hdl: hdl,
+		}
+	})
+
+	return route
+}
+
+func ProvideHandler(svc domain.SubscriptionService) *Handler {
+	hdlOnce.Do(func() {
+		hdl = &Handler{
+			svc: svc,
+		}
+	})
+
+	return hdl
+}
+
+func ProvideService(prpo domain.PlanRepository, srpo domain.SubscriptionRepository, esvc domain.EntitlementService,
+	db *sql.DB) *Service {
+	svcOnce.Do(func() {
+		svc = &Service{
+			prpo: prpo,
+			srpo: srpo,
+			esvc: esvc,
+			db:   db,
+		}
+	})
+
+	return svc
+}
+
+func ProvideEntitlementService(prpo domain.PlanRepository, srpo domain.SubscriptionRepository, db *sql.DB) *EntitlementService {
+	esvcOnce.Do(func() {
+		esvc = &EntitlementService{
+			prpo: prpo,
+			srpo: srpo,
+			db:   db,
+		}
+	})
+
+	return esvc
+}
+
+func ProvideRepository() *Repository {
+	srpoOnce.Do(func() {
+		srpo = new(Repository)
+	})
+
+	return srpo
+}
+
+func ProvidePlanRepository() *PlanRepository {
+	prpoOnce.Do(func() {
+		prpo = new(PlanRepository)
+	})
+
+	return prpo
+}
@@ -0,0 +1,140 @@
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+	"go-edash/enums"
+	"time"
+)
+
+// Repository stores the subscriptions of the company the context is scoped to.
+type Repository struct {
+}
+
+// Create subscribes the company the context is scoped to.
+func (rpo *Repository) Create(ctx context.Context, tx *sql.Tx, subscription *domain.Subscription) *domain.Subscription {
+	subscription.CompanyId = domain.MustTenant(ctx)
+
+	query := `insert into subscriptions (id,company_id,plan_id,status,period_start,period_end)
+	values (?,?,?,?,?,?)`
+
+	_, err := tx.ExecContext(ctx, query, subscription.Id, subscription.CompanyId, subscription.PlanId,
+		subscription.Status, subscription.PeriodStart, subscription.PeriodEnd)
+	if err != nil {
+		panic(err)
+	}
+
+	return subscription
+}
+
+// Expire ends every subscription of the company right away.
+func (rpo *Repository) Expire(ctx context.Context, tx *sql.Tx) {
+	query := "update subscriptions set status = ? where company_id = ? and status in (?,?)"
+
+	_, err := tx.ExecContext(ctx, query, enums.SUBSCRIPTION_EXPIRED, domain.MustTenant(ctx), enums.SUBSCRIPTION_ACTIVE,
+		enums.SUBSCRIPTION_CANCELED)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FindCurrent returns the subscription of the company whose period covers the given moment.
+// Canceled subscriptions are returned as well, as they stay usable until the end of their period.
+func (rpo *Repository) FindCurrent(ctx context.Context, tx *sql.Tx, at time.Time) (*domain.Subscription, error) {
+	query := `select id, company_id, plan_id, status, period_start, period_end, canceled_at from subscriptions
+	where company_id = ? and status in (?,?) and period_start <= ? and period_end > ?
+	order by period_end desc limit 1`
+
+	rows, err := tx.QueryContext(ctx, query, domain.MustTenant(ctx), enums.SUBSCRIPTION_ACTIVE,
+		enums.SUBSCRIPTION_CANCELED, at, at)
+	if err != nil {
+		panic(err)
+	}
+
+	return scanSubscription(rows)
+}
+
+// FindLatest returns the subscription of the company ending last after the given moment, including the
+// subscriptions whose period has not started yet.
+func (rpo *Repository) FindLatest(ctx context.Context, tx *sql.Tx, at time.Time) (*domain.Subscription, error) {
+	query := `select id, company_id, plan_id, status, period_start, period_end, canceled_at from subscriptions
+	where company_id = ? and status in (?,?) and period_end > ?
+	order by period_end desc limit 1`
+
+	rows, err := tx.QueryContext(ctx, query, domain.MustTenant(ctx), enums.SUBSCRIPTION_ACTIVE,
+		enums.SUBSCRIPTION_CANCELED, at)
+	if err != nil {
+		panic(err)
+	}
+
+	return scanSubscription(rows)
+}
+
+// scanSubscription scans the first row into a subscription and closes the rows.
+func scanSubscription(rows *sql.Rows) (*domain.Subscription, error) {
+	defer rows.Close()
+
+	subscription := new(domain.Subscription)
+	if rows.Next() {
+		err := rows.Scan(&subscription.Id, &subscription.CompanyId, &subscription.PlanId, &subscription.Status,
+			&subscription.PeriodStart, &subscription.PeriodEnd, &subscription.CanceledAt)
+		if err != nil {
+			panic(err)
+		}
+
+		return subscription, nil
+	} else {
+		return subscription, errors.New("subscription not found")
+	}
+}
+
+// FindTrialStart returns the start date of the latest running trial among the members of the company.
+func (rpo *Repository) FindTrialStart(ctx context.Context, tx *sql.Tx) (time.Time, error) {
+	query := `select max(u.trial_start_date) from users u join company_members m on m.user_id = u.id
+	where m.company_id = ? and u.status_trial = true`
+
+	rows, err := tx.QueryContext(ctx, query, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var start sql.NullTime
+	if rows.Next() {
+		err = rows.Scan(&start)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if !start.Valid {
+		return time.Time{}, errors.New("trial not found")
+	}
+
+	return start.Time, nil
+}
+
+// CountMembers returns the number of seats used in the company.
+func (rpo *Repository) CountMembers(ctx context.Context, tx *sql.Tx) int64 {
+	query := "select count(*) from company_members where company_id = ?"
+
+	rows, err := tx.QueryContext(ctx, query, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var count int64
+	if rows.Next() {
+		err = rows.Scan(&count)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,25 @@
+package subscription
+
+import (
+	"github.com/go-chi/chi/v5"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/middlewares"
+)
+
+type Router struct {
+	hdl domain.SubscriptionHandler
+}
+
+func (router *Router) InitializeRoute(rtr *chi.Mux) {
+	rtr.Route("/api/subscription", func(route chi.Router) {
+		route.Get("/plans", router.hdl.GetPlans())
+
+		route.Group(func(secure chi.Router) {
+			secure.Use(middlewares.AuthorizationCheckMiddleware)
+			secure.Use(middlewares.VerifyTokenMiddleware)
+			secure.With(middlewares.RequirePermission(enums.SUBSCRIPTION_READ)).
+				Get("/current", router.hdl.GetSubscription())
+		})
+	})
+}
@@ -0,0 +1,83 @@
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/domain"
+	"go-edash/exceptions"
+	"go-edash/utils"
+)
+
+type Service struct {
+	prpo domain.PlanRepository
+	srpo domain.SubscriptionRepository
+	esvc domain.EntitlementService
+	db   *sql.DB
+}
+
+func nullableLimit(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Int64
+}
+
+func planResponse(plan *domain.Plan) domain.PlanResponse {
+	return domain.PlanResponse{
+		Code:  plan.Code,
+		Name:  plan.Name,
+		Price: plan.Price,
+		Limits: domain.PlanLimitsResponse{
+			MaxMembers:    nullableLimit(plan.MaxMembers),
+			MaxDashboards: nullableLimit(plan.MaxDashboards),
+			MaxApiCalls:   nullableLimit(plan.MaxApiCalls),
+		},
+	}
+}
+
+// GetPlans returns the plans catalog, from the cheapest plan.
+func (svc *Service) GetPlans(ctx context.Context) []domain.PlanResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	plans := svc.prpo.FindAll(ctx, tx)
+
+	responses := make([]domain.PlanResponse, 0, len(plans))
+	for _, plan := range plans {
+		responses = append(responses, planResponse(plan))
+	}
+
+	return responses
+}
+
+// GetSubscription returns the plan the company of the authenticated user is entitled to, and its seat usage.
+// It panics with a PaymentRequiredError when the company has neither a subscription nor a running trial.
+func (svc *Service) GetSubscription(ctx context.Context) domain.SubscriptionResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	if _, ok := domain.TenantFromContext(ctx); !ok {
+		panic(exceptions.NewNotFoundError("company not found"))
+	}
+
+	entitlements, entitled := svc.esvc.Resolve(ctx, tx)
+	if !entitled {
+		panic(exceptions.NewPaymentRequiredError("no active subscription"))
+	}
+
+	return domain.SubscriptionResponse{
+		Plan:        planResponse(entitlements.Plan),
+		Trial:       entitlements.Trial,
+		ActiveUntil: entitlements.ActiveUntil,
+		SeatsUsed:   svc.srpo.CountMembers(ctx, tx),
+	}
+}
@@ -0,0 +1,13 @@
+//go:build wireinject
+// +build wireinject
+
+package subscription
+
+import (
+	"database/sql"
+	"github.com/google/wire"
+)
+
+func Wire(db *sql.DB) *Router {
+	panic(wire.Build(ProviderSet))
+}
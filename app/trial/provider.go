@@ -0,0 +1,45 @@
+package trial
+
+import (
+	"database/sql"
+	"github.com/google/wire"
+	"go-edash/app/outbox"
+	"go-edash/domain"
+	"sync"
+)
+
+var (
+	svc     *Service
+	svcOnce sync.Once
+
+	rpo     *Repository
+	rpoOnce sync.Once
+
+	ProviderSet = wire.NewSet(
+		outbox.ServiceSet,
+		ProvideService,
+		ProvideRepository,
+		wire.Bind(new(domain.TrialService), new(*Service)),
+		wire.Bind(new(domain.TrialRepository), new(*Repository)),
+	)
+)
+
+func ProvideService(rpo domain.TrialRepository, db *sql.DB, outbox domain.OutboxService) *Service {
+	svcOnce.Do(func() {
+		svc = &Service{
+			rpo:    rpo,
+			db:     db,
+			outbox: outbox,
+		}
+	})
+
+	return svc
+}
+
+func ProvideRepository() *Repository {
+	rpoOnce.Do(func() {
+		rpo = new(Repository)
+	})
+
+	return rpo
+}
@@ -0,0 +1,75 @@
+package trial
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/domain"
+	"time"
+)
+
+type Repository struct {
+}
+
+// ExpireTrials ends the running trials that started on or before the given date.
+// It returns the number of trials ended.
+func (rpo *Repository) ExpireTrials(ctx context.Context, tx *sql.Tx, startedOnOrBefore time.Time) int64 {
+	query := "update users set status_trial = false where status_trial = true and trial_start_date <= ?"
+
+	result, err := tx.ExecContext(ctx, query, startedOnOrBefore)
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+
+	return affected
+}
+
+// FindActiveTrials returns the users whose running trial started on or before the given date.
+func (rpo *Repository) FindActiveTrials(ctx context.Context, tx *sql.Tx, startedOnOrBefore time.Time) []*domain.User {
+	query := `select id, email, first_name, last_name, status_trial, trial_start_date, locale from users
+	where status_trial = true and trial_start_date <= ?`
+
+	rows, err := tx.QueryContext(ctx, query, startedOnOrBefore)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user := new(domain.User)
+
+		err = rows.Scan(&user.Id, &user.Email, &user.FirstName, &user.LastName, &user.StatusTrial, &user.TrialStartDate,
+			&user.Locale)
+		if err != nil {
+			panic(err)
+		}
+
+		users = append(users, user)
+	}
+
+	return users
+}
+
+// MarkReminderSent records that the reminder has been sent to the user.
+// It returns false when it had already been sent, so that every reminder is only sent once.
+func (rpo *Repository) MarkReminderSent(ctx context.Context, tx *sql.Tx, userId string, reminder string) bool {
+	query := "insert ignore into trial_reminders (user_id,reminder) values (?,?)"
+
+	result, err := tx.ExecContext(ctx, query, userId, reminder)
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+
+	return affected == 1
+}
@@ -0,0 +1,77 @@
+package trial
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/utils"
+	"strconv"
+	"time"
+)
+
+// reminderDays lists how many days before the end of a trial its reminders are sent,
+// from the closest to the end of the trial.
+var reminderDays = []int{1, 7}
+
+type Service struct {
+	rpo    domain.TrialRepository
+	db     *sql.DB
+	outbox domain.OutboxService
+}
+
+// RunDailyJob ends the trials that are over and reminds the users whose trial is about to end.
+//
+// A reminder is sent 7 days and 1 day before the end of a trial. Every reminder is only sent once, and a user
+// who missed a reminder (e.g. because the job did not run that day) only gets the latest one due.
+func (svc *Service) RunDailyJob(ctx context.Context) {
+	log := config.CreateLoggers(nil)
+
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	length := config.TrialLengthDays()
+
+	expired := svc.rpo.ExpireTrials(ctx, tx, today.AddDate(0, 0, -length))
+
+	log.Info(fmt.Sprintf("%d trials expired", expired))
+
+	firstReminder := reminderDays[len(reminderDays)-1]
+
+	for _, user := range svc.rpo.FindActiveTrials(ctx, tx, today.AddDate(0, 0, firstReminder-length)) {
+		end := config.TrialEnd(user.TrialStartDate.Time)
+		daysLeft := int(end.Sub(today).Hours() / 24)
+
+		for _, days := range reminderDays {
+			if daysLeft > days {
+				continue
+			}
+
+			if svc.rpo.MarkReminderSent(ctx, tx, user.Id, "T-"+strconv.Itoa(days)) {
+				svc.sendReminder(ctx, tx, user, end, daysLeft)
+			}
+
+			break
+		}
+	}
+}
+
+// sendReminder tells the user that their trial ends soon.
+func (svc *Service) sendReminder(ctx context.Context, tx *sql.Tx, user *domain.User, end time.Time, daysLeft int) {
+	lastDay := end.AddDate(0, 0, -1).Format("02-01-2006")
+
+	svc.outbox.EnqueueTemplate(ctx, tx, user.Email, user.FirstName+" "+user.LastName, user.Locale,
+		enums.EMAIL_TRIAL_REMINDER, map[string]any{
+			"Name":     user.FirstName,
+			"DaysLeft": daysLeft,
+			"LastDay":  lastDay,
+		})
+}
@@ -0,0 +1,14 @@
+//go:build wireinject
+// +build wireinject
+
+package trial
+
+import (
+	"database/sql"
+	"github.com/google/wire"
+	"go-edash/domain"
+)
+
+func Wire(db *sql.DB, mail domain.Mailer, messenger domain.Messenger) *Service {
+	panic(wire.Build(ProviderSet))
+}
@@ -0,0 +1,57 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+type EmailChangeRepository struct {
+}
+
+func (rpo *EmailChangeRepository) Create(ctx context.Context, tx *sql.Tx, change *domain.EmailChange) *domain.EmailChange {
+	query := "insert into email_changes (id,user_id,new_email) values (?,?,?)"
+
+	_, err := tx.ExecContext(ctx, query, change.Id, change.UserId, change.NewEmail)
+	if err != nil {
+		panic(err)
+	}
+
+	return change
+}
+
+// FindPendingByUser returns the latest email change of the user that has not been consumed yet.
+func (rpo *EmailChangeRepository) FindPendingByUser(ctx context.Context, tx *sql.Tx, userId string) (*domain.EmailChange, error) {
+	query := `select id, user_id, new_email, consumed_at from email_changes
+	where user_id = ? and consumed_at is null order by created_at desc limit 1 for update`
+
+	rows, err := tx.QueryContext(ctx, query, userId)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	change := new(domain.EmailChange)
+	if rows.Next() {
+		err = rows.Scan(&change.Id, &change.UserId, &change.NewEmail, &change.ConsumedAt)
+		if err != nil {
+			panic(err)
+		}
+
+		return change, nil
+	} else {
+		return change, errors.New("email change not found")
+	}
+}
+
+// InvalidateByUser marks every pending email change of the user as consumed.
+func (rpo *EmailChangeRepository) InvalidateByUser(ctx context.Context, tx *sql.Tx, userId string) {
+	query := "update email_changes set consumed_at = current_timestamp where user_id = ? and consumed_at is null"
+
+	_, err := tx.ExecContext(ctx, query, userId)
+	if err != nil {
+		panic(err)
+	}
+}
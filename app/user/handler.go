@@ -107,6 +107,423 @@ func (hdl *Handler) RegisterBasicWithSSO() http.HandlerFunc {
 	}
 }
 
+// Login is an HTTP handler function that authenticates a user with email and password.
+// It expects a JSON payload in the request body that conforms to the LoginRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// It returns a JSON response with an access token and a refresh token.
+// The response status code is set to 200 OK.
+func (hdl *Handler) Login() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		// Decode the request payload into a LoginRequest struct
+		req := new(domain.LoginRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		// Validate the request payload
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		// Authenticate the user using the UserService
+		ctx := request.Context()
+		result := hdl.svc.Login(ctx, req)
+
+		// Create a JSON response with the tokens
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		// Set the response headers
+		writer.Header().Set("Content-Type", "application/json")
+
+		// Encode the response into the writer
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// LoginWithSSO is an HTTP handler function that authenticates a user with an ID token of the identity provider.
+// It expects a JSON payload in the request body that conforms to the LoginWithSSORequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// It returns a JSON response with an access token and a refresh token.
+// The response status code is set to 200 OK.
+func (hdl *Handler) LoginWithSSO() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		// Decode the request payload into a LoginWithSSORequest struct
+		req := new(domain.LoginWithSSORequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		// Validate the request payload
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		// Authenticate the user using the UserService
+		ctx := request.Context()
+		result := hdl.svc.LoginWithSSO(ctx, req)
+
+		// Create a JSON response with the tokens
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		// Set the response headers
+		writer.Header().Set("Content-Type", "application/json")
+
+		// Encode the response into the writer
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RefreshToken is an HTTP handler function that exchanges a refresh token for a new pair of tokens.
+// It expects a JSON payload in the request body that conforms to the RefreshTokenRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) RefreshToken() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		// Decode the request payload into a RefreshTokenRequest struct
+		req := new(domain.RefreshTokenRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		// Validate the request payload
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		// Rotate the refresh token using the UserService
+		ctx := request.Context()
+		result := hdl.svc.RefreshToken(ctx, req)
+
+		// Create a JSON response with the new tokens
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		// Set the response headers
+		writer.Header().Set("Content-Type", "application/json")
+
+		// Encode the response into the writer
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Logout is an HTTP handler function that ends the session of the current access token.
+// It revokes the access token and its refresh tokens using the UserService.
+// The response status code is set to 200 OK.
+func (hdl *Handler) Logout() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		hdl.svc.Logout(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// LogoutAllDevices is an HTTP handler function that ends every session of the authenticated user.
+// The response status code is set to 200 OK.
+func (hdl *Handler) LogoutAllDevices() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		hdl.svc.LogoutAllDevices(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ForgotPassword is an HTTP handler function that sends a password reset link.
+// It expects a JSON payload in the request body that conforms to the ForgotPasswordRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is always set to 200 OK, so it does not reveal whether the email is registered.
+func (hdl *Handler) ForgotPassword() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.ForgotPasswordRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		hdl.svc.RequestPasswordReset(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ResetPassword is an HTTP handler function that sets a new password with a password reset token.
+// It expects a JSON payload in the request body that conforms to the ResetPasswordRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) ResetPassword() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.ResetPasswordRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		hdl.svc.ResetPassword(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ChangePassword is an HTTP handler function that changes the password of the authenticated user.
+// It expects a JSON payload in the request body that conforms to the ChangePasswordRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) ChangePassword() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.ChangePasswordRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		hdl.svc.ChangePassword(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (hdl *Handler) UpdateLocale() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.UpdateLocaleRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.UpdateLocale(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ChangeEmail is an HTTP handler function that sends an OTP to the new email of the authenticated user.
+// It expects a JSON payload in the request body that conforms to the ChangeEmailRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) ChangeEmail() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.ChangeEmailRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		hdl.svc.RequestEmailChange(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ConfirmEmailChange is an HTTP handler function that replaces the email of the authenticated user once the OTP is confirmed.
+// It expects a JSON payload in the request body that conforms to the ConfirmEmailChangeRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) ConfirmEmailChange() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.ConfirmEmailChangeRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		hdl.svc.ConfirmEmailChange(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
 // GetByEmail is an HTTP handler function that retrieves a user by email.
 // It expects the email to be passed as a query parameter in the request URL.
 // It returns a JSON response with the user data.
@@ -152,7 +569,7 @@ func (hdl *Handler) GetByEmail() http.HandlerFunc {
 // It validates the request payload using the validator package.
 // If the validation fails, it panics with the error.
 // It checks the OTP using the UserService.
-// If the OTP is valid, it returns a JSON response with the status code set to 201 Created.
+// If the OTP is valid, it returns a JSON response with fresh tokens and the status code set to 200 OK.
 func (hdl *Handler) VerificationOTP() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		// Decode the request payload into a VerificationOTPRequest struct
@@ -172,12 +589,13 @@ func (hdl *Handler) VerificationOTP() http.HandlerFunc {
 
 		// Check the OTP using the UserService
 		ctx := request.Context()
-		hdl.svc.CheckVerificationOTP(ctx, req)
+		result := hdl.svc.CheckVerificationOTP(ctx, req)
 
 		// Create a default response with the status code set to 200 OK
 		svcResponse := response.DefaultResponse{
 			Code:   http.StatusOK,
 			Status: http.StatusText(http.StatusOK),
+			Data:   result,
 		}
 
 		// Set the response headers
@@ -0,0 +1,71 @@
+package user
+
+import (
+	"encoding/json"
+	"go-edash/domain"
+	"go-edash/response"
+	"net/http"
+)
+
+// GetCompanies is an HTTP handler function that returns the companies the authenticated user is a member of.
+// The response status code is set to 200 OK.
+func (hdl *Handler) GetCompanies() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.GetCompanies(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// SwitchCompany is an HTTP handler function that makes another company of the authenticated user the active one.
+// It expects a JSON payload in the request body that conforms to the SwitchCompanyRequest struct.
+// It returns a JSON response with fresh tokens carrying the active company.
+// The response status code is set to 200 OK.
+func (hdl *Handler) SwitchCompany() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.SwitchCompanyRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.SwitchCompany(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
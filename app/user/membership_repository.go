@@ -0,0 +1,133 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+type MembershipRepository struct {
+}
+
+// membershipColumns lists the columns read by scanMembership, in the order they are scanned.
+const membershipColumns = "m.user_id, m.company_id, c.name, m.role"
+
+func scanMembership(rows *sql.Rows) *domain.Membership {
+	membership := new(domain.Membership)
+
+	err := rows.Scan(&membership.UserId, &membership.CompanyId, &membership.CompanyName, &membership.Role)
+	if err != nil {
+		panic(err)
+	}
+
+	return membership
+}
+
+func (rpo *MembershipRepository) Create(ctx context.Context, tx *sql.Tx, membership *domain.Membership) *domain.Membership {
+	query := "insert into company_members (user_id,company_id,role) values (?,?,?)"
+
+	_, err := tx.ExecContext(ctx, query, membership.UserId, membership.CompanyId, membership.Role)
+	if err != nil {
+		panic(err)
+	}
+
+	return membership
+}
+
+// UpdateRole changes the role of the user in the company the context is scoped to.
+func (rpo *MembershipRepository) UpdateRole(ctx context.Context, tx *sql.Tx, membership *domain.Membership) *domain.Membership {
+	query := "update company_members set role = ? where user_id = ? and company_id = ?"
+
+	result, err := tx.ExecContext(ctx, query, membership.Role, membership.UserId, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	domain.MustAffectTenant(result, "membership")
+
+	return membership
+}
+
+// Delete removes the user from the company the context is scoped to.
+func (rpo *MembershipRepository) Delete(ctx context.Context, tx *sql.Tx, userId string) {
+	query := "delete from company_members where user_id = ? and company_id = ?"
+
+	result, err := tx.ExecContext(ctx, query, userId, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	domain.MustAffectTenant(result, "membership")
+}
+
+func (rpo *MembershipRepository) Find(ctx context.Context, tx *sql.Tx, userId string, companyId string) (*domain.Membership, error) {
+	query := "select " + membershipColumns + ` from company_members m join companies c on c.id = m.company_id
+	where m.user_id = ? and m.company_id = ?`
+
+	rows, err := tx.QueryContext(ctx, query, userId, companyId)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanMembership(rows), nil
+	} else {
+		return new(domain.Membership), errors.New("membership not found")
+	}
+}
+
+// FindAllByUser returns the memberships of the user, in the order the user joined the companies.
+func (rpo *MembershipRepository) FindAllByUser(ctx context.Context, tx *sql.Tx, userId string) []*domain.Membership {
+	query := "select " + membershipColumns + ` from company_members m join companies c on c.id = m.company_id
+	where m.user_id = ? order by m.created_at`
+
+	rows, err := tx.QueryContext(ctx, query, userId)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var memberships []*domain.Membership
+	for rows.Next() {
+		memberships = append(memberships, scanMembership(rows))
+	}
+
+	return memberships
+}
+
+// FindMember returns the membership of the user in the company the context is scoped to.
+func (rpo *MembershipRepository) FindMember(ctx context.Context, tx *sql.Tx, userId string) (*domain.Membership, error) {
+	return rpo.Find(ctx, tx, userId, domain.MustTenant(ctx))
+}
+
+// FindMembers returns the members of the company the context is scoped to.
+func (rpo *MembershipRepository) FindMembers(ctx context.Context, tx *sql.Tx) []*domain.Member {
+	query := `select u.id, u.email, u.first_name, u.last_name, m.role
+	from company_members m join users u on u.id = m.user_id
+	where m.company_id = ? order by u.first_name, u.last_name`
+
+	rows, err := tx.QueryContext(ctx, query, domain.MustTenant(ctx))
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var members []*domain.Member
+	for rows.Next() {
+		member := new(domain.Member)
+
+		err = rows.Scan(&member.UserId, &member.Email, &member.FirstName, &member.LastName, &member.Role)
+		if err != nil {
+			panic(err)
+		}
+
+		members = append(members, member)
+	}
+
+	return members
+}
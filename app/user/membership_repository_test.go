@@ -0,0 +1,51 @@
+package user
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"go-edash/domain"
+	"go-edash/exceptions"
+	"regexp"
+	"testing"
+)
+
+func TestDeleteRemovesTheMembershipOfTheTenantOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	mock.ExpectBegin()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := domain.NewTenantContext(context.Background(), "company-a")
+	query := regexp.QuoteMeta("delete from company_members where user_id = ? and company_id = ?")
+
+	// A member of company B only is not found in company A, so nothing is deleted
+	mock.ExpectExec(query).WithArgs("user-b", "company-a").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	func() {
+		defer func() {
+			if _, ok := recover().(exceptions.NotFoundError); !ok {
+				t.Fatal("expected a NotFoundError")
+			}
+		}()
+
+		new(MembershipRepository).Delete(ctx, tx, "user-b")
+	}()
+
+	// A member of company A is removed from company A alone
+	mock.ExpectExec(query).WithArgs("user-a", "company-a").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	new(MembershipRepository).Delete(ctx, tx, "user-a")
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
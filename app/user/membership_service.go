@@ -0,0 +1,74 @@
+package user
+
+import (
+	"context"
+	"go-edash/domain"
+	"go-edash/exceptions"
+	"go-edash/utils"
+)
+
+// GetCompanies returns the companies the authenticated user is a member of, with the role of the user in each
+// of them, and which one is active.
+func (svc *Service) GetCompanies(ctx context.Context) []domain.MembershipResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	memberships := svc.mrpo.FindAllByUser(ctx, tx, principal.UserId)
+
+	responses := make([]domain.MembershipResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		responses = append(responses, domain.MembershipResponse{
+			CompanyId:   membership.CompanyId,
+			CompanyName: membership.CompanyName,
+			Role:        membership.Role,
+			Active:      membership.CompanyId == principal.CompanyId,
+		})
+	}
+
+	return responses
+}
+
+// SwitchCompany makes another company of the authenticated user the active one and returns fresh tokens carrying
+// it, with the role of the user in that company. The tokens of the request are rotated, so they stop working.
+//
+// The active company is remembered, so the next sessions of the user start in it. Companies forcing two-factor
+// authentication cannot be switched to until the user has enabled it.
+func (svc *Service) SwitchCompany(ctx context.Context, request *domain.SwitchCompanyRequest) domain.AuthResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	membership, errMembership := svc.mrpo.Find(ctx, tx, user.Id, request.CompanyId)
+	if errMembership != nil {
+		panic(exceptions.NewNotFoundError("company not found"))
+	}
+
+	twoFactor, errTwoFactor := svc.trpo.FindByUser(ctx, tx, user.Id)
+	enabled := errTwoFactor == nil && twoFactor.EnabledAt.Valid
+
+	if !enabled && svc.trpo.IsRequiredByCompany(ctx, tx, membership.CompanyId) {
+		panic(exceptions.NewForbiddenError("two-factor authentication is required by the company"))
+	}
+
+	user.CompanyId = membership.CompanyId
+
+	svc.rpo.Update(ctx, tx, user)
+
+	return svc.reissueTokens(ctx, tx, user)
+}
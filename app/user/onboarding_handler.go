@@ -0,0 +1,56 @@
+package user
+
+import (
+	"encoding/json"
+	"go-edash/response"
+	"net/http"
+)
+
+// GetOnboarding is an HTTP handler function that returns the current onboarding step of the authenticated user.
+// The response status code is set to 200 OK.
+func (hdl *Handler) GetOnboarding() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.GetOnboarding(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// CompleteOnboarding is an HTTP handler function that completes the onboarding of the authenticated user.
+// It returns a JSON response with fresh tokens carrying the new onboarding step.
+// The response status code is set to 200 OK.
+func (hdl *Handler) CompleteOnboarding() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.CompleteOnboarding(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"go-edash/utils"
+	"time"
+)
+
+// GetOnboarding returns the current onboarding step of the authenticated user and the step that comes next.
+// The step is read from the database, so it is accurate even when the access token has not been refreshed yet.
+func (svc *Service) GetOnboarding(ctx context.Context) domain.OnboardingResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	response := domain.OnboardingResponse{
+		Step:        user.RegistrationStep.String(),
+		EmailStatus: user.EmailStatus,
+	}
+
+	if next, ok := user.RegistrationStep.Next(); ok {
+		response.NextStep = next.String()
+	}
+
+	return response
+}
+
+// CompleteOnboarding moves the authenticated user from the COMPANY_CREATED step to the DONE step,
+// which starts the free trial of the user. It returns an AuthResponse holding fresh tokens, which carry the new onboarding step of the user.
+func (svc *Service) CompleteOnboarding(ctx context.Context) domain.AuthResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	if user.RegistrationStep == enums.DONE {
+		panic(exceptions.NewDuplicateError("onboarding already completed"))
+	}
+
+	if user.RegistrationStep != enums.COMPANY_CREATED {
+		panic(exceptions.NewForbiddenError("onboarding step " + enums.COMPANY_CREATED.String() + " required"))
+	}
+
+	user.RegistrationStep = enums.DONE
+	user.StatusTrial = true
+	user.TrialStartDate = sql.NullTime{Time: time.Now(), Valid: true}
+
+	svc.rpo.Update(ctx, tx, user)
+
+	return svc.reissueTokens(ctx, tx, user)
+}
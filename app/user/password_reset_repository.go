@@ -0,0 +1,55 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+type PasswordResetRepository struct {
+}
+
+func (rpo *PasswordResetRepository) Create(ctx context.Context, tx *sql.Tx, reset *domain.PasswordReset) *domain.PasswordReset {
+	query := "insert into password_resets (id,user_id,token_hash,expires_at) values (?,?,?,?)"
+
+	_, err := tx.ExecContext(ctx, query, reset.Id, reset.UserId, reset.TokenHash, reset.ExpiresAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return reset
+}
+
+func (rpo *PasswordResetRepository) FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (*domain.PasswordReset, error) {
+	query := "select id, user_id, token_hash, expires_at, used_at from password_resets where token_hash = ? for update"
+
+	rows, err := tx.QueryContext(ctx, query, tokenHash)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	reset := new(domain.PasswordReset)
+	if rows.Next() {
+		err = rows.Scan(&reset.Id, &reset.UserId, &reset.TokenHash, &reset.ExpiresAt, &reset.UsedAt)
+		if err != nil {
+			panic(err)
+		}
+
+		return reset, nil
+	} else {
+		return reset, errors.New("password reset not found")
+	}
+}
+
+// InvalidateByUser marks every pending password reset of the user as used.
+func (rpo *PasswordResetRepository) InvalidateByUser(ctx context.Context, tx *sql.Tx, userId string) {
+	query := "update password_resets set used_at = current_timestamp where user_id = ? and used_at is null"
+
+	_, err := tx.ExecContext(ctx, query, userId)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,181 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"go-edash/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// phoneCodeThreshold is the number of verification codes a user can request before being locked out.
+	// Every message costs money, so the sends are limited and not only the failed attempts.
+	phoneCodeThreshold = 5
+
+	// ipPhoneCodeThreshold is the number of verification codes an IP address can request before being locked out.
+	ipPhoneCodeThreshold = 20
+)
+
+// limitSend counts a verification code about to be sent and refuses it with a 429 response once the account or the
+// IP address of the client has requested too many codes. Unlike the failed attempts, the codes sent are never
+// cleared, they are only forgotten once the window of the AttemptLimiter has passed.
+func (svc *Service) limitSend(ctx context.Context, account string) {
+	accountKey, ipKey := attemptKeys(ctx, "phone-code", account)
+
+	// The threshold is reached by the first code refused, past the number of codes allowed
+	wait := max(svc.limiter.Fail(ctx, ipKey, ipPhoneCodeThreshold+1),
+		svc.limiter.Fail(ctx, accountKey, phoneCodeThreshold+1))
+	if wait > 0 {
+		panic(exceptions.NewTooManyRequestsError("too many verification codes requested", wait))
+	}
+}
+
+// defaultChannel returns the channel the phone verification codes are sent through when the user does not choose
+// one, read from PHONE_OTP_CHANNEL and defaulting to WHATSAPP, which most of the users prefer.
+func defaultChannel() enums.MessageChannel {
+	channel := enums.MessageChannel(strings.ToUpper(viper.GetString("PHONE_OTP_CHANNEL")))
+	if channel != enums.CHANNEL_SMS {
+		return enums.CHANNEL_WHATSAPP
+	}
+
+	return channel
+}
+
+// phoneCodeText returns the text of the message holding a phone verification code, in the locale.
+func phoneCodeText(locale enums.Locale, otp string, minutes int) string {
+	if locale == enums.LOCALE_EN {
+		return "Your EDash verification code is " + otp + ". It is valid for " + strconv.Itoa(minutes) +
+			" minutes. Do not share this code with anyone."
+	}
+
+	return "Kode verifikasi EDash Anda adalah " + otp + ". Kode ini berlaku selama " + strconv.Itoa(minutes) +
+		" menit. Jangan berikan kode ini kepada siapa pun."
+}
+
+// RequestPhoneVerification sends a verification code to the new phone number of the authenticated user, by
+// WhatsApp unless another channel is requested. The code is queued in the outbox and only sent once the request
+// is committed.
+//
+// The number is normalized to the E.164 format, numbers without a country code being Indonesian ones. It is kept
+// as the pending phone number of the user until the code is confirmed with ConfirmPhoneVerification, and only then
+// replaces the current phone number, which stays verified in the meantime. Requesting a new code invalidates the
+// previous ones. Too many requests, like repeated failures to confirm a code, lock the account and the IP address
+// of the client out.
+func (svc *Service) RequestPhoneVerification(ctx context.Context,
+	request *domain.PhoneVerificationRequest) domain.PhoneVerificationResponse {
+	principal := domain.MustPrincipal(ctx)
+
+	var result domain.PhoneVerificationResponse
+
+	svc.limitAttempts(ctx, "phone", principal.UserId, func() {
+		result = svc.requestPhoneVerification(ctx, request)
+	})
+
+	return result
+}
+
+func (svc *Service) requestPhoneVerification(ctx context.Context,
+	request *domain.PhoneVerificationRequest) domain.PhoneVerificationResponse {
+	phoneNumber, errPhone := utils.NormalizePhoneNumber(request.PhoneNumber)
+	if errPhone != nil {
+		panic(exceptions.NewUnprocessableEntityError(errPhone.Error()))
+	}
+
+	channel := request.Channel
+	if channel == "" {
+		channel = defaultChannel()
+	}
+
+	principal := domain.MustPrincipal(ctx)
+
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	if user.PhoneNumber == phoneNumber && user.PhoneVerifiedAt.Valid {
+		panic(exceptions.NewDuplicateError("phone number already verified"))
+	}
+
+	svc.limitSend(ctx, user.Id)
+
+	user.PendingPhoneNumber = phoneNumber
+
+	svc.rpo.Update(ctx, tx, user)
+
+	otp, expiresAt := svc.osvc.Issue(ctx, tx, enums.OTP_PHONE_VERIFICATION, user.Id)
+
+	svc.outbox.EnqueueMessage(ctx, tx, channel, &domain.Message{
+		ToPhone: phoneNumber,
+		Locale:  user.Locale,
+		Text:    phoneCodeText(user.Locale, otp, int(time.Until(expiresAt).Round(time.Minute).Minutes())),
+		Otp:     otp,
+	})
+
+	return domain.PhoneVerificationResponse{
+		PhoneNumber: phoneNumber,
+		Channel:     channel,
+		ExpiresAt:   expiresAt,
+	}
+}
+
+// ConfirmPhoneVerification verifies the pending phone number of the authenticated user with the code sent to it,
+// which then becomes the phone number of the user.
+//
+// Repeated failures lock the account and the IP address of the client out.
+func (svc *Service) ConfirmPhoneVerification(ctx context.Context,
+	request *domain.ConfirmPhoneVerificationRequest) domain.UserResponse {
+	principal := domain.MustPrincipal(ctx)
+
+	var result domain.UserResponse
+
+	svc.limitAttempts(ctx, "phone", principal.UserId, func() {
+		result = svc.confirmPhoneVerification(ctx, request)
+	})
+
+	return result
+}
+
+func (svc *Service) confirmPhoneVerification(ctx context.Context,
+	request *domain.ConfirmPhoneVerificationRequest) domain.UserResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	if user.PendingPhoneNumber == "" {
+		panic(exceptions.NewNotFoundError("phone number not found"))
+	}
+
+	svc.osvc.Verify(ctx, tx, enums.OTP_PHONE_VERIFICATION, user.Id, request.Otp)
+
+	user.PhoneNumber = user.PendingPhoneNumber
+	user.PendingPhoneNumber = ""
+	user.PhoneVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	svc.rpo.Update(ctx, tx, user)
+
+	return userResponse(user)
+}
@@ -4,7 +4,8 @@ import (
 	"database/sql"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
-	"github.com/mailjet/mailjet-apiv3-go/v4"
+	"go-edash/app/otp"
+	"go-edash/app/outbox"
 	"go-edash/domain"
 	"sync"
 )
@@ -22,14 +23,41 @@ var (
 	rpo     *Repository
 	rpoOnce sync.Once
 
+	srpo     *SessionRepository
+	srpoOnce sync.Once
+
+	prpo     *PasswordResetRepository
+	prpoOnce sync.Once
+
+	erpo     *EmailChangeRepository
+	erpoOnce sync.Once
+
+	trpo     *TwoFactorRepository
+	trpoOnce sync.Once
+
+	mrpo     *MembershipRepository
+	mrpoOnce sync.Once
+
 	ProviderSet = wire.NewSet(
+		outbox.ServiceSet,
+		otp.ProviderSet,
 		ProvideRouter,
 		ProvideHandler,
 		ProvideService,
 		ProvideRepository,
+		ProvideSessionRepository,
+		ProvidePasswordResetRepository,
+		ProvideEmailChangeRepository,
+		ProvideTwoFactorRepository,
+		ProvideMembershipRepository,
 		wire.Bind(new(domain.UserHandler), new(*Handler)),
 		wire.Bind(new(domain.UserService), new(*Service)),
 		wire.Bind(new(domain.UserRepository), new(*Repository)),
+		wire.Bind(new(domain.SessionRepository), new(*SessionRepository)),
+		wire.Bind(new(domain.PasswordResetRepository), new(*PasswordResetRepository)),
+		wire.Bind(new(domain.EmailChangeRepository), new(*EmailChangeRepository)),
+		wire.Bind(new(domain.TwoFactorRepository), new(*TwoFactorRepository)),
+		wire.Bind(new(domain.MembershipRepository), new(*MembershipRepository)),
 	)
 )
 
@@ -54,12 +82,24 @@ func ProvideHandler(validate *validator.Validate, svc domain.UserService) *Handl
 	return hdl
 }
 
-func ProvideService(rpo domain.UserRepository, db *sql.DB, mail *mailjet.Client) *Service {
+func ProvideService(rpo domain.UserRepository, srpo domain.SessionRepository, prpo domain.PasswordResetRepository,
+	erpo domain.EmailChangeRepository, trpo domain.TwoFactorRepository, mrpo domain.MembershipRepository, db *sql.DB,
+	outbox domain.OutboxService, osvc domain.OtpService, suppressions domain.EmailSuppressionRepository,
+	limiter domain.AttemptLimiter) *Service {
 	svcOnce.Do(func() {
 		svc = &Service{
-			rpo:  rpo,
-			db:   db,
-			mail: mail,
+			rpo:    rpo,
+			srpo:   srpo,
+			prpo:   prpo,
+			erpo:   erpo,
+			trpo:   trpo,
+			mrpo:   mrpo,
+			db:     db,
+			outbox: outbox,
+			osvc:   osvc,
+
+			suppressions: suppressions,
+			limiter:      limiter,
 		}
 	})
 
@@ -73,3 +113,43 @@ func ProvideRepository() *Repository {
 
 	return rpo
 }
+
+func ProvideSessionRepository() *SessionRepository {
+	srpoOnce.Do(func() {
+		srpo = new(SessionRepository)
+	})
+
+	return srpo
+}
+
+func ProvidePasswordResetRepository() *PasswordResetRepository {
+	prpoOnce.Do(func() {
+		prpo = new(PasswordResetRepository)
+	})
+
+	return prpo
+}
+
+func ProvideEmailChangeRepository() *EmailChangeRepository {
+	erpoOnce.Do(func() {
+		erpo = new(EmailChangeRepository)
+	})
+
+	return erpo
+}
+
+func ProvideTwoFactorRepository() *TwoFactorRepository {
+	trpoOnce.Do(func() {
+		trpo = new(TwoFactorRepository)
+	})
+
+	return trpo
+}
+
+func ProvideMembershipRepository() *MembershipRepository {
+	mrpoOnce.Do(func() {
+		mrpo = new(MembershipRepository)
+	})
+
+	return mrpo
+}
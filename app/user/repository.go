@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"go-edash/domain"
+	"go-edash/enums"
 )
 
 type Repository struct {
@@ -27,14 +28,19 @@ func getLastId(ctx context.Context, tx *sql.Tx, email string) string {
 	return lastId
 }
 
+// Create creates the user, in the default locale unless another one is set.
 func (rpo *Repository) Create(ctx context.Context, tx *sql.Tx, user *domain.User) *domain.User {
-	query := `insert into users (id,email,password,phone_number,first_name,last_name,role,provider,provider_id,otp,
-    otp_expired_time,registration_step,status_trial,trial_start_date)
-	values (?,?,?,?,?,?,?,?,?,?,?,?,?,?)`
+	if user.Locale == "" {
+		user.Locale = enums.DEFAULT_LOCALE
+	}
+
+	query := `insert into users (id,email,password,phone_number,first_name,last_name,role,provider,provider_id,
+    registration_step,status_trial,trial_start_date,locale)
+	values (?,?,?,?,?,?,?,?,?,?,?,?,?)`
 
 	_, err := tx.ExecContext(ctx, query, user.Id, user.Email, user.Password, user.PhoneNumber, user.FirstName,
-		user.LastName, user.Role, user.Provider, user.ProviderId, user.Otp, user.OtpExpiredTime, user.RegistrationStep,
-		user.StatusTrial, user.TrialStartDate)
+		user.LastName, user.Role, user.Provider, user.ProviderId, user.RegistrationStep, user.StatusTrial,
+		user.TrialStartDate, user.Locale)
 	if err != nil {
 		panic(err)
 	}
@@ -48,35 +54,115 @@ func (rpo *Repository) Create(ctx context.Context, tx *sql.Tx, user *domain.User
 
 func (rpo *Repository) Update(ctx context.Context, tx *sql.Tx, user *domain.User) *domain.User {
 	query := `update users set password=?,phone_number=?,first_name=?,last_name=?,role=?,provider=?,
-    provider_id=?,otp=?,otp_expired_time=?,registration_step=?,status_trial=?,trial_start_date=?,company_id=?
+    provider_id=?,registration_step=?,status_trial=?,trial_start_date=?,company_id=?,locale=?,phone_verified_at=?,
+    pending_phone_number=?
 	where email = ?`
 
 	_, err := tx.ExecContext(ctx, query, user.Password, user.PhoneNumber, user.FirstName, user.LastName, user.Role,
-		user.Provider, user.ProviderId, user.Otp, user.OtpExpiredTime, user.RegistrationStep, user.StatusTrial,
-		user.TrialStartDate, user.CompanyId, user.Email)
+		user.Provider, user.ProviderId, user.RegistrationStep, user.StatusTrial, user.TrialStartDate,
+		user.CompanyId, user.Locale, user.PhoneVerifiedAt, user.PendingPhoneNumber, user.Email)
+	if err != nil {
+		panic(err)
+	}
+
+	return user
+}
+
+// userColumns lists the columns read by scanUser, in the order they are scanned.
+// The delivery status of the email is the reason it is suppressed for, if it is.
+const userColumns = `id, email, password, phone_number, first_name, last_name, role, provider, provider_id,
+	registration_step, status_trial, trial_start_date, company_id, locale, phone_verified_at, pending_phone_number,
+	(select reason from email_suppressions where email_suppressions.email = users.email)`
+
+// scanUser scans the current row into a user.
+// The rows must select userColumns. Nullable columns are read as empty strings, so that writing the user back
+// with Update keeps every column it was loaded with.
+func scanUser(rows *sql.Rows) *domain.User {
+	var phoneNumber, pendingPhoneNumber, provider, providerId, companyId, emailStatus sql.NullString
+
+	user := new(domain.User)
+
+	err := rows.Scan(&user.Id, &user.Email, &user.Password, &phoneNumber, &user.FirstName, &user.LastName, &user.Role,
+		&provider, &providerId, &user.RegistrationStep, &user.StatusTrial, &user.TrialStartDate, &companyId,
+		&user.Locale, &user.PhoneVerifiedAt, &pendingPhoneNumber, &emailStatus)
+	if err != nil {
+		panic(err)
+	}
+
+	user.PhoneNumber = phoneNumber.String
+	user.PendingPhoneNumber = pendingPhoneNumber.String
+	user.Provider = provider.String
+	user.ProviderId = providerId.String
+	user.CompanyId = companyId.String
+
+	user.EmailStatus = enums.EMAIL_DELIVERABLE
+	if emailStatus.Valid {
+		user.EmailStatus = enums.EmailStatus(emailStatus.String)
+	}
+
+	return user
+}
+
+func (rpo *Repository) UpdateEmail(ctx context.Context, tx *sql.Tx, user *domain.User, email string) *domain.User {
+	query := "update users set email=? where id = ?"
+
+	_, err := tx.ExecContext(ctx, query, email, user.Id)
 	if err != nil {
 		panic(err)
 	}
 
+	user.Email = email
+
 	return user
 }
 
 func (rpo *Repository) FindByEmail(ctx context.Context, tx *sql.Tx, email string) (*domain.User, error) {
-	query := `select id, email, password, first_name, last_name, otp, otp_expired_time, company_id
-	from users where email = ?`
+	query := "select " + userColumns + " from users where email = ?"
 
 	rows, err := tx.QueryContext(ctx, query, email)
 	if err != nil {
 		panic(err)
 	}
 
-	user := new(domain.User)
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanUser(rows), nil
+	} else {
+		return new(domain.User), errors.New("user not found")
+	}
+}
+
+func (rpo *Repository) FindById(ctx context.Context, tx *sql.Tx, id string) (*domain.User, error) {
+	query := "select " + userColumns + " from users where id = ?"
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
 	if rows.Next() {
-		err = rows.Scan(&user.Id, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.Otp,
-			&user.OtpExpiredTime, &user.CompanyId)
+		return scanUser(rows), nil
+	} else {
+		return new(domain.User), errors.New("user not found")
+	}
+}
+
+func (rpo *Repository) FindByProvider(ctx context.Context, tx *sql.Tx, provider string, providerId string) (*domain.User, error) {
+	query := "select " + userColumns + " from users where provider = ? and provider_id = ?"
 
-		return user, nil
+	rows, err := tx.QueryContext(ctx, query, provider, providerId)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanUser(rows), nil
 	} else {
-		return user, errors.New("user not found")
+		return new(domain.User), errors.New("user not found")
 	}
 }
@@ -3,6 +3,7 @@ package user
 import (
 	"github.com/go-chi/chi/v5"
 	"go-edash/domain"
+	"go-edash/enums"
 	"go-edash/middlewares"
 )
 
@@ -14,13 +15,36 @@ func (router *Router) InitializeRoute(rtr *chi.Mux) {
 	rtr.Route("/api/user", func(route chi.Router) {
 		route.Post("/register/basic/without-sso", router.hdl.RegisterBasicWithoutSSO())
 		route.Post("/register/basic/with-sso", router.hdl.RegisterBasicWithSSO())
+		route.Post("/login", router.hdl.Login())
+		route.Post("/login/sso", router.hdl.LoginWithSSO())
+		route.Post("/login/two-factor", router.hdl.LoginWithTwoFactor())
+		route.Post("/login/two-factor/enroll", router.hdl.EnrollTwoFactorAtLogin())
+		route.Post("/login/two-factor/activate", router.hdl.ActivateTwoFactorAtLogin())
+		route.Post("/refresh", router.hdl.RefreshToken())
+		route.Post("/password/forgot", router.hdl.ForgotPassword())
+		route.Post("/password/reset", router.hdl.ResetPassword())
 
 		route.Group(func(secure chi.Router) {
 			secure.Use(middlewares.AuthorizationCheckMiddleware)
 			secure.Use(middlewares.VerifyTokenMiddleware)
-			secure.Get("/check-email", router.hdl.GetByEmail())
+			secure.With(middlewares.RequirePermission(enums.USER_READ)).Get("/check-email", router.hdl.GetByEmail())
+			secure.Get("/onboarding", router.hdl.GetOnboarding())
+			secure.Post("/onboarding/complete", router.hdl.CompleteOnboarding())
+			secure.Get("/companies", router.hdl.GetCompanies())
+			secure.Post("/switch-company", router.hdl.SwitchCompany())
 			secure.Post("/verification-otp", router.hdl.VerificationOTP())
 			secure.Post("/generate-otp", router.hdl.GenerateOTP())
+			secure.Post("/logout", router.hdl.Logout())
+			secure.Post("/logout-all", router.hdl.LogoutAllDevices())
+			secure.Post("/password/change", router.hdl.ChangePassword())
+			secure.Post("/email/change", router.hdl.ChangeEmail())
+			secure.Post("/email/confirm", router.hdl.ConfirmEmailChange())
+			secure.Post("/locale", router.hdl.UpdateLocale())
+			secure.Post("/phone", router.hdl.RequestPhoneVerification())
+			secure.Post("/phone/confirm", router.hdl.ConfirmPhoneVerification())
+			secure.Post("/two-factor/enroll", router.hdl.EnrollTwoFactor())
+			secure.Post("/two-factor/activate", router.hdl.ActivateTwoFactor())
+			secure.Post("/two-factor/disable", router.hdl.DisableTwoFactor())
 		})
 	})
 }
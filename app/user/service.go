@@ -3,26 +3,193 @@ package user
 import (
 	"context"
 	"database/sql"
-	"github.com/mailjet/mailjet-apiv3-go/v4"
 	"github.com/spf13/viper"
 	"go-edash/config"
 	"go-edash/domain"
 	"go-edash/enums"
 	"go-edash/exceptions"
 	"go-edash/utils"
-	"sync"
+	"strings"
 	"time"
 )
 
 type Service struct {
-	rpo  domain.UserRepository
-	db   *sql.DB
-	mail *mailjet.Client
+	rpo    domain.UserRepository
+	srpo   domain.SessionRepository
+	prpo   domain.PasswordResetRepository
+	erpo   domain.EmailChangeRepository
+	trpo   domain.TwoFactorRepository
+	mrpo   domain.MembershipRepository
+	db     *sql.DB
+	outbox domain.OutboxService
+	osvc   domain.OtpService
+
+	suppressions domain.EmailSuppressionRepository
+	limiter      domain.AttemptLimiter
 }
 
-func (svc *Service) SaveRegisterBasicWithoutSSO(ctx context.Context, request *domain.RegisterBasicWithoutSSORequest) domain.AuthResponse {
-	log := config.CreateLoggers(nil)
+// dummyPasswordHash is a bcrypt hash with the cost of utils.Hash, whose password is never used.
+const dummyPasswordHash = "$2a$14$Pv/XDTbQE8Rq7Y6U.aejO.4VDfN1ZsUTzQAMiV9lLLZ.12IpzgpSC"
+
+const (
+	// accountAttemptThreshold is the number of failed attempts after which an account is locked out.
+	accountAttemptThreshold = 5
+
+	// ipAttemptThreshold is the number of failed attempts after which an IP address is locked out.
+	ipAttemptThreshold = 20
+)
+
+// attemptKeys returns the keys counting the attempts of the scope made on the account and from the IP address of
+// the client.
+func attemptKeys(ctx context.Context, scope string, account string) (string, string) {
+	return scope + ":account:" + strings.ToLower(account), scope + ":ip:" + domain.ClientIPFromContext(ctx)
+}
+
+// limitAttempts runs an attempt made on an account and protects the account against brute force.
+//
+// The attempt is refused with a 429 response while the account or the IP address of the client is locked out.
+// When fn panics with a NotMatchedError or an UnauthorizedError, the failure is recorded for both of them.
+// A successful attempt clears the failures of the account.
+func (svc *Service) limitAttempts(ctx context.Context, scope string, account string, fn func()) {
+	accountKey, ipKey := attemptKeys(ctx, scope, account)
+
+	for _, key := range []string{accountKey, ipKey} {
+		if wait := svc.limiter.Check(ctx, key); wait > 0 {
+			panic(exceptions.NewTooManyRequestsError("too many failed attempts", wait))
+		}
+	}
+
+	defer func() {
+		err := recover()
+		if err == nil {
+			svc.limiter.Reset(ctx, accountKey)
+			return
+		}
+
+		_, notMatched := err.(exceptions.NotMatchedError)
+		_, unauthorized := err.(exceptions.UnauthorizedError)
+
+		if notMatched || unauthorized {
+			svc.limiter.Fail(ctx, ipKey, ipAttemptThreshold)
+			svc.limiter.Fail(ctx, accountKey, accountAttemptThreshold)
+		}
+
+		panic(err)
+	}()
+
+	fn()
+}
+
+// activeCompany returns the company the user works in and the role of the user in that company.
+//
+// The active company is the one the user last switched to. A user who is not a member of it, such as a user
+// who has not created a company yet, has no active company and keeps the role of the account.
+func (svc *Service) activeCompany(ctx context.Context, tx *sql.Tx, user *domain.User) (string, enums.Role) {
+	if user.CompanyId == "" {
+		return "", user.Role
+	}
+
+	membership, err := svc.mrpo.Find(ctx, tx, user.Id, user.CompanyId)
+	if err != nil {
+		return "", user.Role
+	}
+
+	return membership.CompanyId, membership.Role
+}
+
+// issueTokens mints an access token and a new refresh token for the given session family.
+// The refresh token is only returned to the client, the session row keeps its SHA-256 hash.
+// The access token carries the active company of the user and the role of the user in it.
+func (svc *Service) issueTokens(ctx context.Context, tx *sql.Tx, user *domain.User, familyId string) domain.AuthResponse {
+	sessionId, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	refreshToken, errRefresh := utils.TokenGenerator(32)
+	if errRefresh != nil {
+		panic(errRefresh)
+	}
+
+	svc.srpo.Create(ctx, tx, &domain.Session{
+		Id:        sessionId,
+		UserId:    user.Id,
+		FamilyId:  familyId,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(config.RefreshTokenTTL()),
+	})
+
+	companyId, role := svc.activeCompany(ctx, tx, user)
+
+	jwtParam := &config.JwtParameters{
+		UserId:    user.Id,
+		Email:     user.Email,
+		Role:      role,
+		CompanyId: companyId,
+		SessionId: familyId,
+
+		RegistrationStep: user.RegistrationStep,
+	}
+
+	if user.TrialStartDate.Valid {
+		jwtParam.TrialEndsAt = config.TrialEnd(user.TrialStartDate.Time)
+	}
+
+	token, errToken := config.GenerateToken(jwtParam)
+	if errToken != nil {
+		panic(errToken)
+	}
+
+	return domain.AuthResponse{
+		Email:        user.Email,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.AccessTokenTTL().Seconds()),
+	}
+}
+
+// reissueTokens replaces the tokens of the authenticated user after a change carried by the tokens.
+//
+// The refresh token of the session is marked as rotated and the access token used for the request is revoked, so
+// that only the tokens returned remain valid. Presenting the old refresh token afterward is treated as a reuse.
+func (svc *Service) reissueTokens(ctx context.Context, tx *sql.Tx, user *domain.User) domain.AuthResponse {
+	principal := domain.MustPrincipal(ctx)
+
+	svc.srpo.MarkFamilyRotated(ctx, tx, principal.SessionId)
+
+	errRevoke := config.RevokeToken(ctx, tx, principal.TokenId, principal.ExpiresAt)
+	if errRevoke != nil {
+		panic(errRevoke)
+	}
+
+	return svc.issueTokens(ctx, tx, user, principal.SessionId)
+}
+
+// createSession starts a new session family for the user and returns its first pair of tokens.
+func (svc *Service) createSession(ctx context.Context, tx *sql.Tx, user *domain.User) domain.AuthResponse {
+	familyId, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	return svc.issueTokens(ctx, tx, user, familyId)
+}
+
+// sendRegistrationOTP issues a registration OTP for the user and sends it to the email of the user.
+func (svc *Service) sendRegistrationOTP(ctx context.Context, tx *sql.Tx, user *domain.User) {
+	otp, expiresAt := svc.osvc.Issue(ctx, tx, enums.OTP_REGISTRATION, user.Id)
 
+	svc.outbox.EnqueueTemplate(ctx, tx, user.Email, user.FirstName+" "+user.LastName, user.Locale,
+		enums.EMAIL_REGISTRATION_OTP, map[string]any{
+			"Name":    user.FirstName,
+			"Otp":     otp,
+			"Minutes": int(time.Until(expiresAt).Round(time.Minute).Minutes()),
+		})
+}
+
+func (svc *Service) SaveRegisterBasicWithoutSSO(ctx context.Context, request *domain.RegisterBasicWithoutSSORequest) domain.AuthResponse {
 	tx, err := svc.db.Begin()
 	if err != nil {
 		panic(err)
@@ -41,7 +208,8 @@ func (svc *Service) SaveRegisterBasicWithoutSSO(ctx context.Context, request *do
 		FirstName:        request.FirstName,
 		LastName:         request.LastName,
 		Role:             enums.ADMIN,
-		RegistrationStep: 0,
+		RegistrationStep: enums.REGISTERED,
+		Locale:           request.Locale,
 	}
 
 	hash, errHash := utils.Hash(user.Password)
@@ -49,82 +217,130 @@ func (svc *Service) SaveRegisterBasicWithoutSSO(ctx context.Context, request *do
 		user.Password = hash
 	}
 
-	otp, errOtp := utils.OTPGenerator(6)
-	if errOtp != nil {
-		panic(errOtp)
+	user = svc.rpo.Create(ctx, tx, user)
+
+	svc.sendRegistrationOTP(ctx, tx, user)
+
+	return svc.createSession(ctx, tx, user)
+}
+
+// verifyIdToken verifies an ID token of the identity provider and returns its claims.
+// Tokens whose email has not been verified by the provider are rejected.
+func verifyIdToken(ctx context.Context, idToken string) *config.IDTokenClaims {
+	claims, err := config.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		panic(exceptions.NewUnauthorizedError("invalid id token: " + err.Error()))
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		panic(exceptions.NewUnauthorizedError("email is not verified by the identity provider"))
 	}
 
-	user.Otp = otp
+	return claims
+}
 
-	timeNow := time.Now()
+// SaveRegisterBasicWithSSO registers a new user from an ID token of the identity provider.
+//
+// The email is taken from the verified claims of the token, and the provider and subject are stored on the user
+// so that later SSO logins map to the same account. The names from the request take precedence over the claims.
+// The provider has already verified the email, so the user starts the onboarding with a verified email.
+func (svc *Service) SaveRegisterBasicWithSSO(ctx context.Context, request *domain.RegisterBasicWithSSORequest) domain.AuthResponse {
+	claims := verifyIdToken(ctx, request.IdToken)
 
-	expiredOtp := timeNow.Add(10 * time.Minute)
-	formatExpiredOtp := expiredOtp.Format("15:04:05")
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
 
-	user.OtpExpiredTime = formatExpiredOtp
+	defer utils.CommitRollback(tx)
 
-	messagesInfo := []mailjet.InfoMessagesV31{
-		{
-			From: &mailjet.RecipientV31{
-				Email: viper.GetString("MJ_EMAIL"),
-				Name:  "EDash Admin",
-			},
-			To: &mailjet.RecipientsV31{
-				mailjet.RecipientV31{
-					Email: user.Email,
-					Name:  user.FirstName + " " + user.LastName,
-				},
-			},
-			Subject:          "Kode Autentikasi EDash",
-			TemplateID:       6184340,
-			TemplateLanguage: true,
-			Variables: map[string]interface{}{
-				"name":  user.FirstName + " " + user.LastName,
-				"email": user.Email,
-				"otp":   user.Otp,
-			},
-		},
+	_, err = svc.rpo.FindByProvider(ctx, tx, claims.Provider, claims.Subject)
+	if err == nil {
+		panic(exceptions.NewDuplicateError("account already registered"))
 	}
 
-	messages := mailjet.MessagesV31{Info: messagesInfo}
+	_, err = svc.rpo.FindByEmail(ctx, tx, claims.Email)
+	if err == nil {
+		panic(exceptions.NewDuplicateError("email already exists"))
+	}
 
-	group := new(sync.WaitGroup)
+	user := &domain.User{
+		Email:            claims.Email,
+		FirstName:        request.FirstName,
+		LastName:         request.LastName,
+		Role:             enums.ADMIN,
+		Provider:         claims.Provider,
+		ProviderId:       claims.Subject,
+		RegistrationStep: enums.EMAIL_VERIFIED,
+		Locale:           request.Locale,
+	}
 
-	go func(group *sync.WaitGroup, messages *mailjet.MessagesV31) {
-		defer group.Done()
+	if user.FirstName == "" {
+		user.FirstName = claims.GivenName
+	}
 
-		_, err = svc.mail.SendMailV31(messages)
-		if err != nil {
-			log.Error(err)
-		}
+	if user.LastName == "" {
+		user.LastName = claims.FamilyName
+	}
 
-		group.Add(1)
-	}(group, &messages)
+	hash, errHash := utils.Hash(user.Password)
+	if errHash == nil {
+		user.Password = hash
+	}
 
 	user = svc.rpo.Create(ctx, tx, user)
 
-	group.Wait()
+	return svc.createSession(ctx, tx, user)
+}
 
-	jwtParam := &config.JwtParameters{
-		Email: user.Email,
-		Role:  user.Role,
+// Login authenticates a user with their email and password.
+//
+// It takes a context.Context and a LoginRequest as parameters.
+// It returns an AuthResponse holding a short-lived access token and the refresh token of a new session,
+// or a two-factor token when the user has to pass a second step first.
+// Repeated failures lock the account and the IP address of the client out.
+func (svc *Service) Login(ctx context.Context, request *domain.LoginRequest) (result domain.AuthResponse) {
+	svc.limitAttempts(ctx, "login", request.Email, func() {
+		result = svc.login(ctx, request)
+	})
+
+	return result
+}
+
+func (svc *Service) login(ctx context.Context, request *domain.LoginRequest) domain.AuthResponse {
+	// Start a new database transaction
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
 	}
 
-	token, errToken := config.GenerateToken(jwtParam)
-	if errToken != nil {
-		panic(errToken)
+	// Defer the transaction commit or rollback
+	defer utils.CommitRollback(tx)
+
+	// Find the user and check the password, without telling which of the two was wrong.
+	// The password is checked against a dummy hash when there is no such user, or when the user has no password,
+	// so that the response takes as long and does not tell which emails are registered.
+	user, errFind := svc.rpo.FindByEmail(ctx, tx, request.Email)
+
+	hash := user.Password
+	if errFind != nil || hash == "" {
+		hash = dummyPasswordHash
 	}
 
-	return domain.AuthResponse{
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Token:     token,
+	if !utils.CheckHash(request.Password, hash) || errFind != nil || user.Password == "" {
+		panic(exceptions.NewUnauthorizedError("invalid email or password"))
 	}
+
+	return svc.completeLogin(ctx, tx, user)
 }
 
-func (svc *Service) SaveRegisterBasicWithSSO(ctx context.Context, request *domain.RegisterBasicWithSSORequest) domain.AuthResponse {
-	log := config.CreateLoggers(nil)
+// LoginWithSSO authenticates a user with an ID token of the identity provider.
+//
+// The user is looked up by the provider and subject of the token, which were stored at registration.
+// It returns an AuthResponse holding the tokens of a new session, or a two-factor token when the user has to
+// pass a second step first.
+func (svc *Service) LoginWithSSO(ctx context.Context, request *domain.LoginWithSSORequest) domain.AuthResponse {
+	claims := verifyIdToken(ctx, request.IdToken)
 
 	tx, err := svc.db.Begin()
 	if err != nil {
@@ -133,176 +349,405 @@ func (svc *Service) SaveRegisterBasicWithSSO(ctx context.Context, request *domai
 
 	defer utils.CommitRollback(tx)
 
-	_, err = svc.rpo.FindByEmail(ctx, tx, request.Email)
-	if err == nil {
-		panic(exceptions.NewDuplicateError("email already exists"))
+	user, errFind := svc.rpo.FindByProvider(ctx, tx, claims.Provider, claims.Subject)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
 	}
 
-	user := &domain.User{
-		Email:            request.Email,
-		FirstName:        request.FirstName,
-		LastName:         request.LastName,
-		Role:             enums.ADMIN,
-		RegistrationStep: 0,
+	return svc.completeLogin(ctx, tx, user)
+}
+
+// RefreshToken exchanges a refresh token for a new pair of tokens.
+//
+// Every refresh token can be used once. Presenting a refresh token that has already been rotated
+// means it was leaked, so the whole session family is revoked and the request is rejected.
+func (svc *Service) RefreshToken(ctx context.Context, request *domain.RefreshTokenRequest) domain.AuthResponse {
+	result, reused := svc.rotateRefreshToken(ctx, request.RefreshToken)
+
+	// The revocation has to be committed before rejecting the request, so the panic happens
+	// outside of the rotation transaction
+	if reused {
+		panic(exceptions.NewUnauthorizedError("refresh token reused, session revoked"))
 	}
 
-	hash, errHash := utils.Hash(user.Password)
-	if errHash == nil {
-		user.Password = hash
+	return result
+}
+
+// rotateRefreshToken marks the presented refresh token as used and issues its successor.
+// It returns true when the refresh token had already been rotated, after revoking its session family.
+func (svc *Service) rotateRefreshToken(ctx context.Context, refreshToken string) (domain.AuthResponse, bool) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
 	}
 
-	otp, errOtp := utils.OTPGenerator(6)
-	if errOtp != nil {
-		panic(errOtp)
+	defer utils.CommitRollback(tx)
+
+	session, errFind := svc.srpo.FindByTokenHash(ctx, tx, utils.HashToken(refreshToken))
+	if errFind != nil {
+		panic(exceptions.NewUnauthorizedError("invalid refresh token"))
 	}
 
-	user.Otp = otp
+	if session.RevokedAt.Valid {
+		panic(exceptions.NewUnauthorizedError("session revoked"))
+	}
 
-	timeNow := time.Now()
+	if session.RotatedAt.Valid {
+		svc.srpo.RevokeFamily(ctx, tx, session.FamilyId)
 
-	expiredOtp := timeNow.Add(10 * time.Minute)
-	formatExpiredOtp := expiredOtp.Format("15:04:05")
+		return domain.AuthResponse{}, true
+	}
 
-	user.OtpExpiredTime = formatExpiredOtp
+	if time.Now().After(session.ExpiresAt) {
+		panic(exceptions.NewUnauthorizedError("refresh token expired"))
+	}
 
-	messagesInfo := []mailjet.InfoMessagesV31{
-		{
-			From: &mailjet.RecipientV31{
-				Email: viper.GetString("MJ_EMAIL"),
-				Name:  "EDash Admin",
-			},
-			To: &mailjet.RecipientsV31{
-				mailjet.RecipientV31{
-					Email: user.Email,
-					Name:  user.FirstName + " " + user.LastName,
-				},
-			},
-			Subject:          "Kode Autentikasi EDash",
-			TemplateID:       6184340,
-			TemplateLanguage: true,
-			Variables: map[string]interface{}{
-				"name":  user.FirstName + " " + user.LastName,
-				"email": user.Email,
-				"otp":   user.Otp,
-			},
-		},
+	user, errUser := svc.rpo.FindById(ctx, tx, session.UserId)
+	if errUser != nil {
+		panic(exceptions.NewUnauthorizedError(errUser.Error()))
 	}
 
-	messages := mailjet.MessagesV31{Info: messagesInfo}
+	svc.srpo.MarkRotated(ctx, tx, session.Id)
 
-	group := new(sync.WaitGroup)
+	return svc.issueTokens(ctx, tx, user, session.FamilyId), false
+}
 
-	go func(group *sync.WaitGroup, messages *mailjet.MessagesV31) {
-		defer group.Done()
+// Logout ends the session of the access token used for the request.
+//
+// The access token itself is revoked until it expires and the refresh tokens of its session
+// family can no longer be rotated. Both are revoked in the same transaction.
+func (svc *Service) Logout(ctx context.Context) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
 
-		_, err = svc.mail.SendMailV31(messages)
-		if err != nil {
-			log.Error(err)
-		}
+	defer utils.CommitRollback(tx)
 
-		group.Add(1)
-	}(group, &messages)
+	principal := domain.MustPrincipal(ctx)
 
-	user = svc.rpo.Create(ctx, tx, user)
+	errRevoke := config.RevokeToken(ctx, tx, principal.TokenId, principal.ExpiresAt)
+	if errRevoke != nil {
+		panic(errRevoke)
+	}
 
-	group.Wait()
+	svc.srpo.RevokeFamily(ctx, tx, principal.SessionId)
+}
 
-	jwtParam := &config.JwtParameters{
-		Email: user.Email,
-		Role:  user.Role,
+// LogoutAllDevices ends every session of the authenticated user.
+//
+// All the refresh tokens of the user are revoked, as well as every access token issued up to now.
+func (svc *Service) LogoutAllDevices(ctx context.Context) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
 	}
 
-	token, errToken := config.GenerateToken(jwtParam)
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	svc.srpo.RevokeByUser(ctx, tx, user.Id)
+
+	errRevoke := config.RevokeSubject(ctx, tx, user.Id)
+	if errRevoke != nil {
+		panic(errRevoke)
+	}
+}
+
+// passwordResetTTL returns how long a password reset link stays valid.
+// It is read from PASSWORD_RESET_TTL and defaults to 30 minutes.
+func passwordResetTTL() time.Duration {
+	ttl := viper.GetDuration("PASSWORD_RESET_TTL")
+	if ttl <= 0 {
+		return 30 * time.Minute
+	}
+
+	return ttl
+}
+
+// RequestPasswordReset sends a password reset link to the email of the request.
+//
+// The link holds a single-use token whose hash is stored with an expiration time, and requesting a new link
+// invalidates the previous ones. Nothing tells the caller whether the email belongs to an account.
+func (svc *Service) RequestPasswordReset(ctx context.Context, request *domain.ForgotPasswordRequest) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	user, token, found := svc.createPasswordReset(ctx, tx, request.Email)
+	if !found {
+		return
+	}
+
+	link := viper.GetString("APP_FRONTEND_URL") + "/reset-password?token=" + token
+
+	svc.outbox.EnqueueTemplate(ctx, tx, user.Email, user.FirstName+" "+user.LastName, user.Locale,
+		enums.EMAIL_PASSWORD_RESET, map[string]any{
+			"Name":    user.FirstName,
+			"Link":    link,
+			"Minutes": int(passwordResetTTL().Minutes()),
+		})
+}
+
+// createPasswordReset stores a new password reset token for the user owning the email.
+// It returns false when there is no such user.
+func (svc *Service) createPasswordReset(ctx context.Context, tx *sql.Tx, email string) (*domain.User, string, bool) {
+	user, errFind := svc.rpo.FindByEmail(ctx, tx, email)
+	if errFind != nil {
+		return nil, "", false
+	}
+
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	token, errToken := utils.TokenGenerator(32)
 	if errToken != nil {
 		panic(errToken)
 	}
 
-	return domain.AuthResponse{
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Token:     token,
+	svc.prpo.InvalidateByUser(ctx, tx, user.Id)
+
+	svc.prpo.Create(ctx, tx, &domain.PasswordReset{
+		Id:        id,
+		UserId:    user.Id,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL()),
+	})
+
+	return user, token, true
+}
+
+// ResetPassword sets a new password with the token of a password reset link.
+//
+// The token can only be used once and every session of the user is revoked on success.
+func (svc *Service) ResetPassword(ctx context.Context, request *domain.ResetPasswordRequest) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	reset, errFind := svc.prpo.FindByTokenHash(ctx, tx, utils.HashToken(request.Token))
+	if errFind != nil || reset.UsedAt.Valid {
+		panic(exceptions.NewNotMatchedError("invalid password reset token"))
+	}
+
+	if time.Now().After(reset.ExpiresAt) {
+		panic(exceptions.NewGoneError("password reset token expired"))
+	}
+
+	user, errUser := svc.rpo.FindById(ctx, tx, reset.UserId)
+	if errUser != nil {
+		panic(exceptions.NewNotFoundError(errUser.Error()))
 	}
+
+	hash, errHash := utils.Hash(request.Password)
+	if errHash != nil {
+		panic(errHash)
+	}
+
+	user.Password = hash
+
+	svc.rpo.Update(ctx, tx, user)
+	svc.prpo.InvalidateByUser(ctx, tx, user.Id)
+	svc.revokeAllSessions(ctx, tx, user)
 }
 
-// GetByEmail retrieves a user by their email.
+// revokeAllSessions revokes every refresh token and every access token issued so far to the user.
+func (svc *Service) revokeAllSessions(ctx context.Context, tx *sql.Tx, user *domain.User) {
+	svc.srpo.RevokeByUser(ctx, tx, user.Id)
+
+	errRevoke := config.RevokeSubject(ctx, tx, user.Id)
+	if errRevoke != nil {
+		panic(errRevoke)
+	}
+}
+
+// ChangePassword replaces the password of the authenticated user.
 //
-// It takes a context.Context and the user's email as parameters.
-// It returns a pointer to a domain.UserResponse.
-func (svc *Service) GetByEmail(ctx context.Context, email string) domain.UserResponse {
-	// Start a new database transaction
+// The current password must be provided. Every session of the user is revoked, so the user has to log in again
+// with the new password, and a notification is sent to the email of the account.
+func (svc *Service) ChangePassword(ctx context.Context, request *domain.ChangePasswordRequest) {
+	principal := domain.MustPrincipal(ctx)
+
+	svc.limitAttempts(ctx, "password", principal.UserId, func() {
+		svc.changePassword(ctx, request)
+	})
+}
+
+func (svc *Service) changePassword(ctx context.Context, request *domain.ChangePasswordRequest) {
 	tx, err := svc.db.Begin()
 	if err != nil {
 		panic(err)
 	}
 
-	// Defer the transaction commit or rollback
 	defer utils.CommitRollback(tx)
 
-	// Find the user by their email
-	user, errFind := svc.rpo.FindByEmail(ctx, tx, email)
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
 	if errFind != nil {
-		// If the user is not found, throw a NotFoundError
 		panic(exceptions.NewNotFoundError(errFind.Error()))
 	}
 
-	// Return the user's response
-	return domain.UserResponse{
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
+	if !utils.CheckHash(request.CurrentPassword, user.Password) {
+		panic(exceptions.NewNotMatchedError("current password not matched"))
 	}
+
+	hash, errHash := utils.Hash(request.Password)
+	if errHash != nil {
+		panic(errHash)
+	}
+
+	user.Password = hash
+
+	svc.rpo.Update(ctx, tx, user)
+	svc.revokeAllSessions(ctx, tx, user)
+
+	svc.outbox.EnqueueTemplate(ctx, tx, user.Email, user.FirstName+" "+user.LastName, user.Locale,
+		enums.EMAIL_PASSWORD_CHANGED, map[string]any{
+			"Name": user.FirstName,
+		})
 }
 
-// CheckVerificationOTP CheckOTPConfirmation verifies the OTP confirmation for a user.
-// It takes a context.Context and an VerificationOTPRequest as parameters.
-// It returns nothing.
-func (svc *Service) CheckVerificationOTP(ctx context.Context, request *domain.VerificationOTPRequest) {
-	// Begin a new database transaction
+// RequestEmailChange starts changing the email of the authenticated user.
+//
+// The current password must be provided. An OTP is sent to the new email, and the email of the account is only
+// replaced once the OTP has been confirmed with ConfirmEmailChange.
+func (svc *Service) RequestEmailChange(ctx context.Context, request *domain.ChangeEmailRequest) {
 	tx, err := svc.db.Begin()
 	if err != nil {
 		panic(err)
 	}
 
-	// Defer the transaction commit or rollback
 	defer utils.CommitRollback(tx)
 
-	// Find the user by their email
-	user, errFind := svc.rpo.FindByEmail(ctx, tx, request.Email)
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
 	if errFind != nil {
-		// If the user is not found, throw a NotFoundError
 		panic(exceptions.NewNotFoundError(errFind.Error()))
 	}
 
-	// Parse the OTP expiration time
-	now := time.Now()
+	if !utils.CheckHash(request.Password, user.Password) {
+		panic(exceptions.NewNotMatchedError("password not matched"))
+	}
+
+	_, errEmail := svc.rpo.FindByEmail(ctx, tx, request.NewEmail)
+	if errEmail == nil {
+		panic(exceptions.NewDuplicateError("email already exists"))
+	}
+
+	suppression, errSuppression := svc.suppressions.Find(ctx, tx, request.NewEmail)
+	if errSuppression == nil {
+		panic(exceptions.NewUnprocessableEntityError("email does not accept emails (" + string(suppression.Reason) + ")"))
+	}
+
+	id, errId := utils.UUIDGenerator()
+	if errId != nil {
+		panic(errId)
+	}
+
+	svc.erpo.InvalidateByUser(ctx, tx, user.Id)
 
-	otpExpiredConvert, errConvert := time.Parse("15:04:05", user.OtpExpiredTime)
-	if errConvert != nil {
-		panic(errConvert)
+	svc.erpo.Create(ctx, tx, &domain.EmailChange{
+		Id:       id,
+		UserId:   user.Id,
+		NewEmail: request.NewEmail,
+	})
+
+	otp, expiresAt := svc.osvc.Issue(ctx, tx, enums.OTP_EMAIL_CHANGE, id)
+
+	svc.outbox.EnqueueTemplate(ctx, tx, request.NewEmail, user.FirstName+" "+user.LastName, user.Locale,
+		enums.EMAIL_EMAIL_CHANGE_OTP, map[string]any{
+			"Name":    user.FirstName,
+			"Otp":     otp,
+			"Minutes": int(time.Until(expiresAt).Round(time.Minute).Minutes()),
+		})
+}
+
+// ConfirmEmailChange replaces the email of the authenticated user once the OTP sent to the new email is confirmed.
+//
+// The email is the subject of the tokens, so every session of the user is revoked and the user has to log in
+// again with the new email. A notification is sent to the old email.
+//
+// Repeated failures lock the account and the IP address of the client out.
+func (svc *Service) ConfirmEmailChange(ctx context.Context, request *domain.ConfirmEmailChangeRequest) {
+	principal := domain.MustPrincipal(ctx)
+
+	svc.limitAttempts(ctx, "email-change", principal.UserId, func() {
+		svc.confirmEmailChange(ctx, request)
+	})
+}
+
+func (svc *Service) confirmEmailChange(ctx context.Context, request *domain.ConfirmEmailChangeRequest) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
 	}
 
-	// Calculate the OTP expiration time
-	otpExpiredTime := time.Date(now.Year(), now.Month(), now.Day(), otpExpiredConvert.Hour(),
-		otpExpiredConvert.Minute(), otpExpiredConvert.Second(), otpExpiredConvert.Nanosecond(), time.UTC)
+	defer utils.CommitRollback(tx)
 
-	// Calculate the difference between the current time and the OTP expiration time
-	difference := now.Sub(otpExpiredTime)
+	principal := domain.MustPrincipal(ctx)
 
-	// If the difference is greater than 10 minutes, throw a GoneError
-	if difference > 10*time.Minute {
-		panic(exceptions.NewGoneError("otp expired"))
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
 	}
 
-	// If the OTP does not match, throw a NotMatchedError
-	if request.Otp != user.Otp {
-		panic(exceptions.NewNotMatchedError("otp not matched"))
+	change, errChange := svc.erpo.FindPendingByUser(ctx, tx, user.Id)
+	if errChange != nil {
+		panic(exceptions.NewNotFoundError(errChange.Error()))
 	}
+
+	svc.osvc.Verify(ctx, tx, enums.OTP_EMAIL_CHANGE, change.Id, request.Otp)
+
+	_, errEmail := svc.rpo.FindByEmail(ctx, tx, change.NewEmail)
+	if errEmail == nil {
+		panic(exceptions.NewDuplicateError("email already exists"))
+	}
+
+	oldEmail := user.Email
+
+	svc.erpo.InvalidateByUser(ctx, tx, user.Id)
+	svc.revokeAllSessions(ctx, tx, user)
+	svc.rpo.UpdateEmail(ctx, tx, user, change.NewEmail)
+
+	svc.outbox.EnqueueTemplate(ctx, tx, oldEmail, user.FirstName+" "+user.LastName, user.Locale,
+		enums.EMAIL_EMAIL_CHANGED, map[string]any{
+			"Name":     user.FirstName,
+			"NewEmail": change.NewEmail,
+		})
 }
 
-func (svc *Service) GenerateNewOTP(ctx context.Context, request *domain.GenerateOTPRequest) {
-	log := config.CreateLoggers(nil)
+func userResponse(user *domain.User) domain.UserResponse {
+	return domain.UserResponse{
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Locale:        user.Locale,
+		EmailStatus:   user.EmailStatus,
+		PhoneNumber:   user.PhoneNumber,
+		PhoneVerified: user.PhoneVerifiedAt.Valid,
+
+		PendingPhoneNumber: user.PendingPhoneNumber,
+	}
+}
 
+// UpdateLocale changes the locale of the authenticated user, in which the emails to the user are written.
+func (svc *Service) UpdateLocale(ctx context.Context, request *domain.UpdateLocaleRequest) domain.UserResponse {
 	tx, err := svc.db.Begin()
 	if err != nil {
 		panic(err)
@@ -310,64 +755,120 @@ func (svc *Service) GenerateNewOTP(ctx context.Context, request *domain.Generate
 
 	defer utils.CommitRollback(tx)
 
-	user, errFind := svc.rpo.FindByEmail(ctx, tx, request.Email)
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
 	if errFind != nil {
 		panic(exceptions.NewNotFoundError(errFind.Error()))
 	}
 
-	otp, errOtp := utils.OTPGenerator(6)
-	if errOtp != nil {
-		panic(errOtp)
+	user.Locale = request.Locale
+
+	svc.rpo.Update(ctx, tx, user)
+
+	return userResponse(user)
+}
+
+// GetByEmail retrieves a user by their email.
+//
+// It takes a context.Context and the user's email as parameters.
+// It returns a pointer to a domain.UserResponse.
+func (svc *Service) GetByEmail(ctx context.Context, email string) domain.UserResponse {
+	// Start a new database transaction
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
 	}
 
-	user.Otp = otp
+	// Defer the transaction commit or rollback
+	defer utils.CommitRollback(tx)
 
-	timeNow := time.Now()
+	// Find the user by their email
+	user, errFind := svc.rpo.FindByEmail(ctx, tx, email)
+	if errFind != nil {
+		// If the user is not found, throw a NotFoundError
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
 
-	expiredOtp := timeNow.Add(10 * time.Minute)
-	formatExpiredOtp := expiredOtp.Format("15:04:05")
+	// Return the user's response
+	return userResponse(user)
+}
 
-	user.OtpExpiredTime = formatExpiredOtp
+// CheckVerificationOTP CheckOTPConfirmation verifies the OTP confirmation for a user.
+// It takes a context.Context and an VerificationOTPRequest as parameters.
+// It returns an AuthResponse holding fresh tokens, which carry the new onboarding step of the user.
+//
+// The email of the request must be the email of the authenticated user. Once the OTP is confirmed, the user
+// moves from the REGISTERED step to the EMAIL_VERIFIED step.
+// Repeated failures lock the account and the IP address of the client out.
+func (svc *Service) CheckVerificationOTP(ctx context.Context, request *domain.VerificationOTPRequest) (result domain.AuthResponse) {
+	svc.limitAttempts(ctx, "otp", request.Email, func() {
+		result = svc.checkVerificationOTP(ctx, request)
+	})
+
+	return result
+}
 
-	messagesInfo := []mailjet.InfoMessagesV31{
-		{
-			From: &mailjet.RecipientV31{
-				Email: viper.GetString("MJ_EMAIL"),
-				Name:  "EDash Admin",
-			},
-			To: &mailjet.RecipientsV31{
-				mailjet.RecipientV31{
-					Email: user.Email,
-					Name:  user.FirstName + " " + user.LastName,
-				},
-			},
-			Subject:          "Kode Autentikasi EDash",
-			TemplateID:       6184340,
-			TemplateLanguage: true,
-			Variables: map[string]interface{}{
-				"name":  user.FirstName + " " + user.LastName,
-				"email": user.Email,
-				"otp":   user.Otp,
-			},
-		},
+func (svc *Service) checkVerificationOTP(ctx context.Context, request *domain.VerificationOTPRequest) domain.AuthResponse {
+	// Begin a new database transaction
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
 	}
 
-	messages := mailjet.MessagesV31{Info: messagesInfo}
+	// Defer the transaction commit or rollback
+	defer utils.CommitRollback(tx)
 
-	group := new(sync.WaitGroup)
+	principal := domain.MustPrincipal(ctx)
 
-	go func(group *sync.WaitGroup, messages *mailjet.MessagesV31) {
-		defer group.Done()
+	// Find the user waiting for the verification of their email
+	user := svc.findUnverifiedUser(ctx, tx, principal, request.Email)
 
-		_, err = svc.mail.SendMailV31(messages)
-		if err != nil {
-			log.Error(err)
-		}
+	// Verify and consume the registration OTP of the user
+	svc.osvc.Verify(ctx, tx, enums.OTP_REGISTRATION, user.Id, request.Otp)
 
-		group.Add(1)
-	}(group, &messages)
+	// Move the user to the next onboarding step
+	user.RegistrationStep = enums.EMAIL_VERIFIED
 
 	svc.rpo.Update(ctx, tx, user)
 
-	group.Wait()
+	return svc.reissueTokens(ctx, tx, user)
+}
+
+// findUnverifiedUser returns the authenticated user, as long as the email is theirs and has not been verified yet.
+func (svc *Service) findUnverifiedUser(ctx context.Context, tx *sql.Tx, principal *domain.Principal, email string) *domain.User {
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	if !strings.EqualFold(user.Email, email) {
+		panic(exceptions.NewForbiddenError("email does not belong to the authenticated user"))
+	}
+
+	if user.RegistrationStep.Reached(enums.EMAIL_VERIFIED) {
+		panic(exceptions.NewDuplicateError("email already verified"))
+	}
+
+	return user
+}
+
+func (svc *Service) GenerateNewOTP(ctx context.Context, request *domain.GenerateOTPRequest) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	user := svc.findUnverifiedUser(ctx, tx, principal, request.Email)
+
+	if user.EmailStatus != enums.EMAIL_DELIVERABLE {
+		panic(exceptions.NewUnprocessableEntityError("email does not accept emails (" + string(user.EmailStatus) +
+			"), change the email of the account"))
+	}
+
+	svc.sendRegistrationOTP(ctx, tx, user)
 }
@@ -0,0 +1,87 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+type SessionRepository struct {
+}
+
+func (rpo *SessionRepository) Create(ctx context.Context, tx *sql.Tx, session *domain.Session) *domain.Session {
+	query := `insert into user_sessions (id,user_id,family_id,token_hash,expires_at) values (?,?,?,?,?)`
+
+	_, err := tx.ExecContext(ctx, query, session.Id, session.UserId, session.FamilyId, session.TokenHash,
+		session.ExpiresAt)
+	if err != nil {
+		panic(err)
+	}
+
+	return session
+}
+
+// FindByTokenHash looks up the session owning the given refresh token hash.
+// The row is locked for the rest of the transaction so that concurrent rotations of the same
+// refresh token are serialized and the second one is detected as a reuse.
+func (rpo *SessionRepository) FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (*domain.Session, error) {
+	query := `select id, user_id, family_id, token_hash, expires_at, rotated_at, revoked_at
+	from user_sessions where token_hash = ? for update`
+
+	rows, err := tx.QueryContext(ctx, query, tokenHash)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	session := new(domain.Session)
+	if rows.Next() {
+		err = rows.Scan(&session.Id, &session.UserId, &session.FamilyId, &session.TokenHash, &session.ExpiresAt,
+			&session.RotatedAt, &session.RevokedAt)
+		if err != nil {
+			panic(err)
+		}
+
+		return session, nil
+	} else {
+		return session, errors.New("session not found")
+	}
+}
+
+func (rpo *SessionRepository) MarkRotated(ctx context.Context, tx *sql.Tx, id string) {
+	query := "update user_sessions set rotated_at = current_timestamp where id = ?"
+
+	_, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (rpo *SessionRepository) MarkFamilyRotated(ctx context.Context, tx *sql.Tx, familyId string) {
+	query := "update user_sessions set rotated_at = current_timestamp where family_id = ? and rotated_at is null"
+
+	_, err := tx.ExecContext(ctx, query, familyId)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (rpo *SessionRepository) RevokeFamily(ctx context.Context, tx *sql.Tx, familyId string) {
+	query := "update user_sessions set revoked_at = current_timestamp where family_id = ? and revoked_at is null"
+
+	_, err := tx.ExecContext(ctx, query, familyId)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (rpo *SessionRepository) RevokeByUser(ctx context.Context, tx *sql.Tx, userId string) {
+	query := "update user_sessions set revoked_at = current_timestamp where user_id = ? and revoked_at is null"
+
+	_, err := tx.ExecContext(ctx, query, userId)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,232 @@
+package user
+
+import (
+	"encoding/json"
+	"go-edash/domain"
+	"go-edash/response"
+	"net/http"
+)
+
+// LoginWithTwoFactor is an HTTP handler function that completes a login with a TOTP code or a recovery code.
+// It expects a JSON payload in the request body that conforms to the TwoFactorLoginRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) LoginWithTwoFactor() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.TwoFactorLoginRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.LoginWithTwoFactor(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// EnrollTwoFactorAtLogin is an HTTP handler function that generates a TOTP secret for a user forced to enroll at login.
+// It expects a JSON payload in the request body that conforms to the TwoFactorChallengeRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) EnrollTwoFactorAtLogin() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.TwoFactorChallengeRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.EnrollTwoFactorAtLogin(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ActivateTwoFactorAtLogin is an HTTP handler function that enables the TOTP secret of a user forced to enroll and completes the login.
+// It expects a JSON payload in the request body that conforms to the TwoFactorActivationRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) ActivateTwoFactorAtLogin() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.TwoFactorActivationRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.ActivateTwoFactorAtLogin(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// EnrollTwoFactor is an HTTP handler function that generates a TOTP secret for the authenticated user.
+// It returns a JSON response with the secret and its otpauth URI.
+// The response status code is set to 200 OK.
+func (hdl *Handler) EnrollTwoFactor() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		result := hdl.svc.EnrollTwoFactor(ctx)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ActivateTwoFactor is an HTTP handler function that enables the TOTP secret of the authenticated user and returns its recovery codes.
+// It expects a JSON payload in the request body that conforms to the TwoFactorCodeRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) ActivateTwoFactor() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.TwoFactorCodeRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		result := hdl.svc.ActivateTwoFactor(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+			Data:   result,
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// DisableTwoFactor is an HTTP handler function that disables two-factor authentication for the authenticated user.
+// It expects a JSON payload in the request body that conforms to the DisableTwoFactorRequest struct.
+// It validates the request payload using the validator package.
+// If the validation fails, it panics with the error.
+// The response status code is set to 200 OK.
+func (hdl *Handler) DisableTwoFactor() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := new(domain.DisableTwoFactorRequest)
+
+		decoder := json.NewDecoder(request.Body)
+		err := decoder.Decode(&req)
+		if err != nil {
+			panic(err)
+		}
+
+		err = hdl.validate.Struct(req)
+		if err != nil {
+			panic(err)
+		}
+
+		ctx := request.Context()
+		hdl.svc.DisableTwoFactor(ctx, req)
+
+		svcResponse := response.DefaultResponse{
+			Code:   http.StatusOK,
+			Status: http.StatusText(http.StatusOK),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(writer)
+
+		err = encoder.Encode(svcResponse)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
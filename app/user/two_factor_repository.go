@@ -0,0 +1,141 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-edash/domain"
+)
+
+type TwoFactorRepository struct {
+}
+
+// Save stores a new pending secret for the user, replacing any previous one.
+// The secret only protects logins once Enable has been called.
+func (rpo *TwoFactorRepository) Save(ctx context.Context, tx *sql.Tx, twoFactor *domain.TwoFactor) *domain.TwoFactor {
+	query := `insert into user_two_factors (user_id,secret,enabled_at,last_used_step) values (?,?,null,0)
+	on duplicate key update secret=values(secret),enabled_at=null,last_used_step=0`
+
+	_, err := tx.ExecContext(ctx, query, twoFactor.UserId, twoFactor.Secret)
+	if err != nil {
+		panic(err)
+	}
+
+	return twoFactor
+}
+
+func (rpo *TwoFactorRepository) FindByUser(ctx context.Context, tx *sql.Tx, userId string) (*domain.TwoFactor, error) {
+	query := "select user_id, secret, enabled_at, last_used_step from user_two_factors where user_id = ? for update"
+
+	rows, err := tx.QueryContext(ctx, query, userId)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	twoFactor := new(domain.TwoFactor)
+	if rows.Next() {
+		err = rows.Scan(&twoFactor.UserId, &twoFactor.Secret, &twoFactor.EnabledAt, &twoFactor.LastUsedStep)
+		if err != nil {
+			panic(err)
+		}
+
+		return twoFactor, nil
+	} else {
+		return twoFactor, errors.New("two-factor authentication not found")
+	}
+}
+
+func (rpo *TwoFactorRepository) Enable(ctx context.Context, tx *sql.Tx, twoFactor *domain.TwoFactor) *domain.TwoFactor {
+	query := "update user_two_factors set enabled_at=current_timestamp,last_used_step=? where user_id = ?"
+
+	_, err := tx.ExecContext(ctx, query, twoFactor.LastUsedStep, twoFactor.UserId)
+	if err != nil {
+		panic(err)
+	}
+
+	return twoFactor
+}
+
+func (rpo *TwoFactorRepository) UpdateLastUsedStep(ctx context.Context, tx *sql.Tx, twoFactor *domain.TwoFactor) *domain.TwoFactor {
+	query := "update user_two_factors set last_used_step=? where user_id = ?"
+
+	_, err := tx.ExecContext(ctx, query, twoFactor.LastUsedStep, twoFactor.UserId)
+	if err != nil {
+		panic(err)
+	}
+
+	return twoFactor
+}
+
+// Delete removes the secret and the recovery codes of the user.
+func (rpo *TwoFactorRepository) Delete(ctx context.Context, tx *sql.Tx, userId string) {
+	_, err := tx.ExecContext(ctx, "delete from user_recovery_codes where user_id = ?", userId)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = tx.ExecContext(ctx, "delete from user_two_factors where user_id = ?", userId)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ReplaceRecoveryCodes deletes the recovery codes of the user and stores the hashes of the new ones.
+func (rpo *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userId string, codeHashes []string) {
+	_, err := tx.ExecContext(ctx, "delete from user_recovery_codes where user_id = ?", userId)
+	if err != nil {
+		panic(err)
+	}
+
+	query := "insert into user_recovery_codes (user_id,code_hash) values (?,?)"
+
+	for _, codeHash := range codeHashes {
+		_, err = tx.ExecContext(ctx, query, userId, codeHash)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ConsumeRecoveryCode marks a recovery code of the user as used.
+// It returns false when the code does not exist or has already been used.
+func (rpo *TwoFactorRepository) ConsumeRecoveryCode(ctx context.Context, tx *sql.Tx, userId string, codeHash string) bool {
+	query := `update user_recovery_codes set used_at = current_timestamp
+	where user_id = ? and code_hash = ? and used_at is null`
+
+	result, err := tx.ExecContext(ctx, query, userId, codeHash)
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+
+	return affected == 1
+}
+
+// IsRequiredByCompany reports whether the company forces two-factor authentication on its members.
+func (rpo *TwoFactorRepository) IsRequiredByCompany(ctx context.Context, tx *sql.Tx, companyId string) bool {
+	query := "select require_two_factor from companies where id = ?"
+
+	rows, err := tx.QueryContext(ctx, query, companyId)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	required := false
+	if rows.Next() {
+		err = rows.Scan(&required)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return required
+}
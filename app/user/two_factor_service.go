@@ -0,0 +1,314 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/exceptions"
+	"go-edash/utils"
+	"strings"
+	"time"
+)
+
+// completeLogin returns the tokens of a new session for a user who passed the first step of a login.
+//
+// When the user has enabled two-factor authentication, or belongs to a company forcing it, no session is created.
+// A two-factor token is returned instead, to be exchanged for a session by LoginWithTwoFactor or, for users who
+// still have to enroll, by ActivateTwoFactorAtLogin.
+func (svc *Service) completeLogin(ctx context.Context, tx *sql.Tx, user *domain.User) domain.AuthResponse {
+	twoFactor, errTwoFactor := svc.trpo.FindByUser(ctx, tx, user.Id)
+	enabled := errTwoFactor == nil && twoFactor.EnabledAt.Valid
+
+	if !enabled && (user.CompanyId == "" || !svc.trpo.IsRequiredByCompany(ctx, tx, user.CompanyId)) {
+		return svc.createSession(ctx, tx, user)
+	}
+
+	token, errToken := config.GenerateTwoFactorToken(user.Id)
+	if errToken != nil {
+		panic(errToken)
+	}
+
+	return domain.AuthResponse{
+		Email:                       user.Email,
+		FirstName:                   user.FirstName,
+		LastName:                    user.LastName,
+		TwoFactorRequired:           enabled,
+		TwoFactorEnrollmentRequired: !enabled,
+		TwoFactorToken:              token,
+	}
+}
+
+// userFromTwoFactorToken returns the user identified by a two-factor token.
+func (svc *Service) userFromTwoFactorToken(ctx context.Context, tx *sql.Tx, token string) *domain.User {
+	userId, errToken := config.VerifyTwoFactorToken(token)
+	if errToken != nil {
+		panic(exceptions.NewUnauthorizedError("invalid two-factor token"))
+	}
+
+	user, errFind := svc.rpo.FindById(ctx, tx, userId)
+	if errFind != nil {
+		panic(exceptions.NewUnauthorizedError(errFind.Error()))
+	}
+
+	return user
+}
+
+// checkTwoFactorCode checks a TOTP code against the secret of the user.
+// A code is only accepted once, so the time step it matched is remembered.
+func (svc *Service) checkTwoFactorCode(ctx context.Context, tx *sql.Tx, twoFactor *domain.TwoFactor, code string) {
+	step, valid := utils.TOTPValidate(twoFactor.Secret, code, time.Now())
+	if !valid || step <= twoFactor.LastUsedStep {
+		panic(exceptions.NewNotMatchedError("two-factor code not matched"))
+	}
+
+	twoFactor.LastUsedStep = step
+
+	svc.trpo.UpdateLastUsedStep(ctx, tx, twoFactor)
+}
+
+// normalizeRecoveryCode lowercases a recovery code and strips the spaces users may type around it.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// enrollTwoFactor generates a new pending TOTP secret for the user.
+func (svc *Service) enrollTwoFactor(ctx context.Context, tx *sql.Tx, user *domain.User) domain.TwoFactorEnrollmentResponse {
+	twoFactor, errTwoFactor := svc.trpo.FindByUser(ctx, tx, user.Id)
+	if errTwoFactor == nil && twoFactor.EnabledAt.Valid {
+		panic(exceptions.NewDuplicateError("two-factor authentication already enabled"))
+	}
+
+	secret, errSecret := utils.TOTPSecretGenerator()
+	if errSecret != nil {
+		panic(errSecret)
+	}
+
+	svc.trpo.Save(ctx, tx, &domain.TwoFactor{
+		UserId: user.Id,
+		Secret: secret,
+	})
+
+	return domain.TwoFactorEnrollmentResponse{
+		Secret: secret,
+		Uri:    utils.TOTPURI(viper.GetString("APP_NAME"), user.Email, secret),
+	}
+}
+
+// activateTwoFactor enables the pending secret of the user once a code generated from it is confirmed.
+// It returns the recovery codes of the user, which are only stored hashed and cannot be shown again.
+func (svc *Service) activateTwoFactor(ctx context.Context, tx *sql.Tx, user *domain.User, code string) []string {
+	twoFactor, errTwoFactor := svc.trpo.FindByUser(ctx, tx, user.Id)
+	if errTwoFactor != nil {
+		panic(exceptions.NewNotFoundError(errTwoFactor.Error()))
+	}
+
+	if twoFactor.EnabledAt.Valid {
+		panic(exceptions.NewDuplicateError("two-factor authentication already enabled"))
+	}
+
+	step, valid := utils.TOTPValidate(twoFactor.Secret, code, time.Now())
+	if !valid {
+		panic(exceptions.NewNotMatchedError("two-factor code not matched"))
+	}
+
+	twoFactor.LastUsedStep = step
+
+	svc.trpo.Enable(ctx, tx, twoFactor)
+
+	codes := make([]string, 10)
+	hashes := make([]string, len(codes))
+
+	for i := range codes {
+		recoveryCode, errCode := utils.RecoveryCodeGenerator()
+		if errCode != nil {
+			panic(errCode)
+		}
+
+		codes[i] = recoveryCode
+		hashes[i] = utils.HashToken(recoveryCode)
+	}
+
+	svc.trpo.ReplaceRecoveryCodes(ctx, tx, user.Id, hashes)
+
+	return codes
+}
+
+// LoginWithTwoFactor completes a login with a TOTP code or a recovery code.
+//
+// It takes the two-factor token returned by the first step of the login.
+// It returns an AuthResponse holding the tokens of a new session.
+// Repeated failures lock the account and the IP address of the client out.
+func (svc *Service) LoginWithTwoFactor(ctx context.Context, request *domain.TwoFactorLoginRequest) (result domain.AuthResponse) {
+	userId, errToken := config.VerifyTwoFactorToken(request.TwoFactorToken)
+	if errToken != nil {
+		panic(exceptions.NewUnauthorizedError("invalid two-factor token"))
+	}
+
+	svc.limitAttempts(ctx, "two-factor", userId, func() {
+		result = svc.loginWithTwoFactor(ctx, request)
+	})
+
+	return result
+}
+
+func (svc *Service) loginWithTwoFactor(ctx context.Context, request *domain.TwoFactorLoginRequest) domain.AuthResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	user := svc.userFromTwoFactorToken(ctx, tx, request.TwoFactorToken)
+
+	twoFactor, errTwoFactor := svc.trpo.FindByUser(ctx, tx, user.Id)
+	if errTwoFactor != nil || !twoFactor.EnabledAt.Valid {
+		panic(exceptions.NewUnauthorizedError("two-factor authentication is not enabled"))
+	}
+
+	if request.Code != "" {
+		svc.checkTwoFactorCode(ctx, tx, twoFactor, request.Code)
+	} else if !svc.trpo.ConsumeRecoveryCode(ctx, tx, user.Id, utils.HashToken(normalizeRecoveryCode(request.RecoveryCode))) {
+		panic(exceptions.NewNotMatchedError("recovery code not matched"))
+	}
+
+	return svc.createSession(ctx, tx, user)
+}
+
+// EnrollTwoFactorAtLogin generates a TOTP secret for a user whose company forces two-factor authentication
+// and who has not enrolled yet. It takes the two-factor token returned by the first step of the login.
+func (svc *Service) EnrollTwoFactorAtLogin(ctx context.Context, request *domain.TwoFactorChallengeRequest) domain.TwoFactorEnrollmentResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	user := svc.userFromTwoFactorToken(ctx, tx, request.TwoFactorToken)
+
+	return svc.enrollTwoFactor(ctx, tx, user)
+}
+
+// ActivateTwoFactorAtLogin enables the secret generated by EnrollTwoFactorAtLogin and completes the login.
+// It returns an AuthResponse holding the tokens of a new session and the recovery codes of the user.
+// Repeated failures lock the account and the IP address of the client out.
+func (svc *Service) ActivateTwoFactorAtLogin(ctx context.Context, request *domain.TwoFactorActivationRequest) (result domain.AuthResponse) {
+	userId, errToken := config.VerifyTwoFactorToken(request.TwoFactorToken)
+	if errToken != nil {
+		panic(exceptions.NewUnauthorizedError("invalid two-factor token"))
+	}
+
+	svc.limitAttempts(ctx, "two-factor", userId, func() {
+		result = svc.activateTwoFactorAtLogin(ctx, request)
+	})
+
+	return result
+}
+
+func (svc *Service) activateTwoFactorAtLogin(ctx context.Context, request *domain.TwoFactorActivationRequest) domain.AuthResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	user := svc.userFromTwoFactorToken(ctx, tx, request.TwoFactorToken)
+
+	codes := svc.activateTwoFactor(ctx, tx, user, request.Code)
+
+	result := svc.createSession(ctx, tx, user)
+	result.RecoveryCodes = codes
+
+	return result
+}
+
+// EnrollTwoFactor generates a TOTP secret for the authenticated user.
+// It returns the secret and the otpauth URI to register it in an authenticator app.
+func (svc *Service) EnrollTwoFactor(ctx context.Context) domain.TwoFactorEnrollmentResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	return svc.enrollTwoFactor(ctx, tx, user)
+}
+
+// ActivateTwoFactor enables the secret generated by EnrollTwoFactor for the authenticated user.
+// It returns the recovery codes of the user.
+func (svc *Service) ActivateTwoFactor(ctx context.Context, request *domain.TwoFactorCodeRequest) domain.RecoveryCodesResponse {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	return domain.RecoveryCodesResponse{
+		RecoveryCodes: svc.activateTwoFactor(ctx, tx, user, request.Code),
+	}
+}
+
+// DisableTwoFactor disables two-factor authentication for the authenticated user.
+//
+// Both the password and a TOTP code must be provided. Members of a company forcing two-factor authentication
+// cannot disable it.
+func (svc *Service) DisableTwoFactor(ctx context.Context, request *domain.DisableTwoFactorRequest) {
+	principal := domain.MustPrincipal(ctx)
+
+	svc.limitAttempts(ctx, "two-factor", principal.UserId, func() {
+		svc.disableTwoFactor(ctx, request)
+	})
+}
+
+func (svc *Service) disableTwoFactor(ctx context.Context, request *domain.DisableTwoFactorRequest) {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	principal := domain.MustPrincipal(ctx)
+
+	user, errFind := svc.rpo.FindById(ctx, tx, principal.UserId)
+	if errFind != nil {
+		panic(exceptions.NewNotFoundError(errFind.Error()))
+	}
+
+	if user.CompanyId != "" && svc.trpo.IsRequiredByCompany(ctx, tx, user.CompanyId) {
+		panic(exceptions.NewForbiddenError("two-factor authentication is required by the company"))
+	}
+
+	if !utils.CheckHash(request.Password, user.Password) {
+		panic(exceptions.NewNotMatchedError("password not matched"))
+	}
+
+	twoFactor, errTwoFactor := svc.trpo.FindByUser(ctx, tx, user.Id)
+	if errTwoFactor != nil || !twoFactor.EnabledAt.Valid {
+		panic(exceptions.NewNotFoundError("two-factor authentication is not enabled"))
+	}
+
+	svc.checkTwoFactorCode(ctx, tx, twoFactor, request.Code)
+
+	svc.trpo.Delete(ctx, tx, user.Id)
+}
@@ -7,9 +7,10 @@ import (
 	"database/sql"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
-	"github.com/mailjet/mailjet-apiv3-go/v4"
+	"go-edash/domain"
 )
 
-func Wire(validate *validator.Validate, db *sql.DB, mail *mailjet.Client) *Router {
+func Wire(validate *validator.Validate, db *sql.DB, mail domain.Mailer, messenger domain.Messenger,
+	limiter domain.AttemptLimiter) *Router {
 	panic(wire.Build(ProviderSet))
 }
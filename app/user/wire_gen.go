@@ -9,14 +9,26 @@ package user
 import (
 	"database/sql"
 	"github.com/go-playground/validator/v10"
-	"github.com/mailjet/mailjet-apiv3-go/v4"
+	"go-edash/app/otp"
+	"go-edash/app/outbox"
+	"go-edash/domain"
 )
 
 // Injectors from wire.go:
 
-func Wire(validate *validator.Validate, db *sql.DB, mail *mailjet.Client) *Router {
+func Wire(validate *validator.Validate, db *sql.DB, mail domain.Mailer, messenger domain.Messenger, limiter domain.AttemptLimiter) *Router {
 	repository := ProvideRepository()
-	service := ProvideService(repository, db, mail)
+	sessionRepository := ProvideSessionRepository()
+	passwordResetRepository := ProvidePasswordResetRepository()
+	emailChangeRepository := ProvideEmailChangeRepository()
+	twoFactorRepository := ProvideTwoFactorRepository()
+	membershipRepository := ProvideMembershipRepository()
+	otpRepository := otp.ProvideRepository()
+	otpService := otp.ProvideService(otpRepository, db)
+	outboxRepository := outbox.ProvideRepository()
+	suppressionRepository := outbox.ProvideSuppressionRepository()
+	outboxService := outbox.ProvideService(outboxRepository, suppressionRepository, db, mail, messenger)
+	service := ProvideService(repository, sessionRepository, passwordResetRepository, emailChangeRepository, twoFactorRepository, membershipRepository, db, outboxService, otpService, suppressionRepository, limiter)
 	handler := ProvideHandler(validate, service)
 	router := ProvideRouter(handler)
 	return router
@@ -1,8 +1,13 @@
 package welcome
 
 import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
 	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/enums"
 	"go-edash/exceptions"
+	"go-edash/mailer"
 	"net/http"
 )
 
@@ -45,3 +50,60 @@ func (hdl *Handler) MethodNotAllowedApi() http.HandlerFunc {
 		}
 	}
 }
+
+// Jwks is a handler function that publishes the public keys verifying the tokens issued by the application.
+//
+// Other services use the JSON web key set to verify the tokens without holding any secret.
+// The response can be cached for 5 minutes, which is the delay to respect before activating a new key.
+func (hdl *Handler) Jwks() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("Cache-Control", "public, max-age=300")
+
+		encoder := json.NewEncoder(writer)
+
+		err := encoder.Encode(config.PublicJWKS())
+		if err != nil {
+			exceptions.InternalServerHandler(writer, err)
+		}
+	}
+}
+
+// PreviewEmail is a handler function that renders an email template with sample data, to check an email without
+// sending it. It is only meant for development and must not be routed in production.
+//
+// The locale query parameter selects the locale of the email, and the format query parameter renders its plain
+// text part with "text" instead of its HTML part.
+func (hdl *Handler) PreviewEmail() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		locale := enums.Locale(request.URL.Query().Get("locale"))
+		if locale == "" {
+			locale = enums.DEFAULT_LOCALE
+		}
+
+		email, err := mailer.Preview(enums.EmailTemplate(chi.URLParam(request, "template")), locale)
+		if err != nil {
+			writer.WriteHeader(http.StatusNotFound)
+
+			_, err = writer.Write([]byte(err.Error()))
+			if err != nil {
+				exceptions.InternalServerHandler(writer, err)
+			}
+
+			return
+		}
+
+		body := email.Html
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		if request.URL.Query().Get("format") == "text" {
+			body = "Subject: " + email.Subject + "\n\n" + email.Text
+			writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+
+		_, err = writer.Write([]byte(body))
+		if err != nil {
+			exceptions.InternalServerHandler(writer, err)
+		}
+	}
+}
@@ -18,8 +18,11 @@ import (
 // Returns:
 // db *sql.DB: A pointer to the established database connection.
 // err error: An error that occurred during the connection establishment process. If no error occurred, it will be nil.
+//
+// clientFoundRows makes updates report the rows they matched rather than the rows they changed, so that the
+// tenant-scoped repositories can tell an update of unchanged values from an update of another company's row.
 func ConnectDatabase() (*sql.DB, error) {
-	db, err := sql.Open(viper.GetString("DB_DATABASE"), viper.GetString("DB_USERNAME")+":@tcp("+viper.GetString("DB_HOST")+":"+viper.GetString("DB_PORT")+")/"+viper.GetString("DB_NAME")+"?parseTime=True&loc=Asia%2FJakarta&charset=utf8&autocommit=false")
+	db, err := sql.Open(viper.GetString("DB_DATABASE"), viper.GetString("DB_USERNAME")+":@tcp("+viper.GetString("DB_HOST")+":"+viper.GetString("DB_PORT")+")/"+viper.GetString("DB_NAME")+"?parseTime=True&loc=Asia%2FJakarta&charset=utf8&autocommit=false&clientFoundRows=true")
 
 	if err != nil {
 		return nil, err
@@ -5,34 +5,89 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/spf13/viper"
 	"go-edash/enums"
+	"go-edash/utils"
 	"time"
 )
 
 type JwtParameters struct {
-	Email string
-	Role  enums.Role
+	UserId    string
+	Email     string
+	Role      enums.Role
+	CompanyId string
+	SessionId string
+
+	RegistrationStep enums.RegistrationStep
+	TrialEndsAt      time.Time
+}
+
+// AccessTokenTTL returns the lifetime of the access tokens minted by GenerateToken.
+// It is read from JWT_ACCESS_TOKEN_TTL (e.g. "15m") and defaults to 15 minutes.
+func AccessTokenTTL() time.Duration {
+	ttl := viper.GetDuration("JWT_ACCESS_TOKEN_TTL")
+	if ttl <= 0 {
+		return 15 * time.Minute
+	}
+
+	return ttl
+}
+
+// RefreshTokenTTL returns the lifetime of the refresh tokens stored for a session.
+// It is read from JWT_REFRESH_TOKEN_TTL (e.g. "720h") and defaults to 30 days.
+func RefreshTokenTTL() time.Duration {
+	ttl := viper.GetDuration("JWT_REFRESH_TOKEN_TTL")
+	if ttl <= 0 {
+		return 30 * 24 * time.Hour
+	}
+
+	return ttl
 }
 
-// GenerateToken generates a JWT token for the given email address.
+// GenerateToken generates a short-lived JWT access token for the given parameters.
 //
-// The function takes an email string as input and uses the provided secret key to sign a JWT token.
-// The token contains the email address in its claims and has an expiration time of 24 hours.
+// The function takes the JwtParameters as input and signs the token with the active key loaded by LoadSigningKeys,
+// whose kid is set in the token header.
+// The token contains the user id as its subject, the email address, the role, the company id, the session id, the onboarding
+// step and the end of the free trial (0 when no trial has started) in its claims and expires after AccessTokenTTL. Every token gets a unique jti claim so it can be revoked individually.
 //
 // The function uses the jwt.NewWithClaims function to create a new token with the specified claims.
-// It then calls the token's SignedString method to generate the token string using the secret key.
+// It then calls the token's SignedString method to generate the token string using the private key.
 //
 // If the token is successfully generated, the function returns the token string and nil.
 // If there is an error during token signing, the function returns an empty string and the corresponding error.
 func GenerateToken(parameters *JwtParameters) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	key, err := activeSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	jti, err := utils.UUIDGenerator()
+	if err != nil {
+		return "", err
+	}
+
+	var trialEnd int64
+	if !parameters.TrialEndsAt.IsZero() {
+		trialEnd = parameters.TrialEndsAt.Unix()
+	}
+
+	token := jwt.NewWithClaims(key.method, jwt.MapClaims{
+		"jti": jti,
+		"typ": "access",
 		"iss": viper.GetString("APP_NAME"),
-		"sub": parameters.Email,
+		"sub": parameters.UserId,
 		"aud": parameters.Role,
-		"exp": time.Now().Add(24 * time.Hour).Unix(),
+		"eml": parameters.Email,
+		"cid": parameters.CompanyId,
+		"sid": parameters.SessionId,
+		"stp": parameters.RegistrationStep,
+		"tre": trialEnd,
+		"exp": time.Now().Add(AccessTokenTTL()).Unix(),
 		"iat": time.Now().Unix(),
 	})
 
-	tokenString, err := token.SignedString([]byte(viper.GetString("JWT_SIGNATURE_KEY")))
+	token.Header["kid"] = key.kid
+
+	tokenString, err := token.SignedString(key.privateKey)
 	if err != nil {
 		return "", err
 	}
@@ -40,26 +95,75 @@ func GenerateToken(parameters *JwtParameters) (string, error) {
 	return tokenString, nil
 }
 
-// VerifyToken verifies a JWT token using the provided secret key.
+// parseToken parses and verifies a JWT token of the given type using the loaded signing keys.
+//
+// It uses the key named by the kid header to validate the token's signature, and rejects the token when its alg
+// header does not match the algorithm of that key or when its typ claim is not the expected one.
+func parseToken(tokenString string, tokenType string) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(viper.GetString("APP_NAME")),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if typ, _ := token.Claims.(jwt.MapClaims)["typ"].(string); typ != tokenType {
+		return nil, errors.New("invalid token")
+	}
+
+	return token, nil
+}
+
+// VerifyToken verifies an access token minted by GenerateToken.
 //
 // The function takes a string token as input and uses the jwt.Parse function to parse and verify the token.
-// It uses a custom function as the key function to validate the token's signature using the JWT_SIGNATURE_KEY.
+// It uses the key named by the kid header to validate the token's signature, and rejects the token when its alg
+// header does not match the algorithm of that key.
 //
-// If the token is successfully parsed and verified, the function returns nil.
+// If the token is successfully parsed and verified, the function returns the token.
 // If there is an error during parsing or verification, the function returns the corresponding error.
 //
-// If the token is not valid (expired, malformed, etc.), the function returns an error with the message "invalid token".
+// If the token is not valid (expired, malformed, not an access token, etc.), the function returns an error
+// with the message "invalid token".
 func VerifyToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		return []byte(viper.GetString("JWT_SIGNATURE_KEY")), nil
-	})
+	return parseToken(tokenString, "access")
+}
+
+// GenerateTwoFactorToken generates the token proving that a user passed the first step of a login.
+//
+// The token identifies the user by id, expires after 5 minutes and can only be exchanged for a session by
+// completing the second step, it is never accepted as an access token.
+func GenerateTwoFactorToken(userId string) (string, error) {
+	key, err := activeSigningKey()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	if !token.Valid {
-		return nil, errors.New("invalid token")
+	token := jwt.NewWithClaims(key.method, jwt.MapClaims{
+		"typ": "two-factor",
+		"iss": viper.GetString("APP_NAME"),
+		"sub": userId,
+		"exp": time.Now().Add(5 * time.Minute).Unix(),
+		"iat": time.Now().Unix(),
+	})
+
+	token.Header["kid"] = key.kid
+
+	return token.SignedString(key.privateKey)
+}
+
+// VerifyTwoFactorToken verifies a token minted by GenerateTwoFactorToken and returns the id of its user.
+func VerifyTwoFactorToken(tokenString string) (string, error) {
+	token, err := parseToken(tokenString, "two-factor")
+	if err != nil {
+		return "", err
 	}
 
-	return token, nil
+	return token.Claims.GetSubject()
 }
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+)
+
+type jwtKey struct {
+	kid        string
+	method     jwt.SigningMethod
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+type jwtKeyring struct {
+	mutex  sync.RWMutex
+	active *jwtKey
+	keys   map[string]*jwtKey
+}
+
+var keyring = &jwtKeyring{keys: map[string]*jwtKey{}}
+
+// signingMethodFor returns the JWT signing method matching the type of a public key.
+func signingMethodFor(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", publicKey)
+	}
+}
+
+// readJwtKey reads a PEM encoded RSA or Ed25519 key.
+// Private keys can be PKCS#8 or PKCS#1 encoded, public keys must be PKIX encoded.
+func readJwtKey(kid string, path string) (*jwtKey, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, errors.New("no pem block found in " + path)
+	}
+
+	key := &jwtKey{kid: kid}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, errParse := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if errParse != nil {
+			return nil, errParse
+		}
+
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key in " + path)
+		}
+
+		key.privateKey = signer
+		key.publicKey = signer.Public()
+	case "RSA PRIVATE KEY":
+		parsed, errParse := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if errParse != nil {
+			return nil, errParse
+		}
+
+		key.privateKey = parsed
+		key.publicKey = parsed.Public()
+	case "PUBLIC KEY":
+		parsed, errParse := x509.ParsePKIXPublicKey(block.Bytes)
+		if errParse != nil {
+			return nil, errParse
+		}
+
+		key.publicKey = parsed
+	default:
+		return nil, errors.New("unsupported pem block " + block.Type + " in " + path)
+	}
+
+	key.method, err = signingMethodFor(key.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// LoadSigningKeys loads the keys used to sign and verify the tokens minted by GenerateToken.
+//
+// Every file of the JWT_KEYS_DIR directory (defaults to "storage/keys") is a key whose kid is the file name:
+// "<kid>.pem" holds a private key and "<kid>.pub.pem" only a public key. RSA keys sign with RS256 and Ed25519
+// keys with EdDSA. All the keys verify tokens and are published by PublicJWKS, and the private key named by
+// JWT_ACTIVE_KID signs the new tokens.
+//
+// Keys are rotated without invalidating the tokens in flight:
+//  1. add the new private key to the directory and reload, so other services see it in the JWKS;
+//  2. once their JWKS caches have expired, point JWT_ACTIVE_KID to the new key and reload;
+//  3. replace the old private key by its public key ("<kid>.pub.pem") and reload;
+//  4. once the longest lived token signed by the old key has expired, delete it and reload.
+//
+// The keys are reloaded by calling the function again, which the application does on SIGHUP.
+// If the directory cannot be read or the active key is missing, the previous keys are kept and an error is returned.
+func LoadSigningKeys() error {
+	dir := viper.GetString("JWT_KEYS_DIR")
+	if dir == "" {
+		dir = "storage/keys"
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]*jwtKey{}
+	for _, file := range files {
+		kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), ".pem"), ".pub")
+
+		key, errKey := readJwtKey(kid, file)
+		if errKey != nil {
+			return errKey
+		}
+
+		// A private key takes precedence over the public key with the same kid
+		if existing, ok := keys[kid]; ok && existing.privateKey != nil {
+			continue
+		}
+
+		keys[kid] = key
+	}
+
+	activeKid := viper.GetString("JWT_ACTIVE_KID")
+
+	active, ok := keys[activeKid]
+	if !ok || active.privateKey == nil {
+		return errors.New("no private key found for JWT_ACTIVE_KID " + activeKid)
+	}
+
+	keyring.mutex.Lock()
+	defer keyring.mutex.Unlock()
+
+	keyring.keys = keys
+	keyring.active = active
+
+	return nil
+}
+
+// activeSigningKey returns the key that signs the new tokens.
+func activeSigningKey() (*jwtKey, error) {
+	keyring.mutex.RLock()
+	defer keyring.mutex.RUnlock()
+
+	if keyring.active == nil {
+		return nil, errors.New("signing keys are not loaded")
+	}
+
+	return keyring.active, nil
+}
+
+// verificationKey returns the key identified by the kid header of a token.
+// The algorithm of the token must be the one of the key, so a token can never pick how it is verified.
+func verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	keyring.mutex.RLock()
+	defer keyring.mutex.RUnlock()
+
+	key, ok := keyring.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method " + token.Method.Alg())
+	}
+
+	return key.publicKey, nil
+}
+
+// PublicJWKS returns the public keys verifying the tokens as a JSON web key set.
+func PublicJWKS() JsonWebKeySet {
+	keyring.mutex.RLock()
+	defer keyring.mutex.RUnlock()
+
+	keySet := JsonWebKeySet{Keys: []JsonWebKey{}}
+	for _, key := range keyring.keys {
+		jwk := JsonWebKey{
+			Kid: key.kid,
+			Use: "sig",
+			Alg: key.method.Alg(),
+		}
+
+		switch publicKey := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+
+		keySet.Keys = append(keySet.Keys, jwk)
+	}
+
+	sort.Slice(keySet.Keys, func(i, j int) bool {
+		return keySet.Keys[i].Kid < keySet.Keys[j].Kid
+	})
+
+	return keySet
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+)
+
+// newOIDCProvider starts an identity provider serving a discovery document and the JWKS of the key.
+// It points OIDC_ISSUER and OIDC_CLIENT_ID at the provider for the duration of the test.
+func newOIDCProvider(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   server.URL,
+			"jwks_uri": server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(JsonWebKeySet{Keys: []JsonWebKey{{
+			Kty: "RSA",
+			Kid: "provider-key",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	viper.Set("OIDC_ISSUER", server.URL)
+	viper.Set("OIDC_CLIENT_ID", "edash-client")
+	viper.Set("OIDC_JWKS_URL", "")
+
+	oidcKeys = &oidcKeySet{}
+
+	t.Cleanup(func() {
+		viper.Set("OIDC_ISSUER", "")
+		viper.Set("OIDC_CLIENT_ID", "")
+		oidcKeys = &oidcKeySet{}
+	})
+
+	return server
+}
+
+func signIDToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "provider-key"
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	providerKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newOIDCProvider(t, providerKey)
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            server.URL,
+			"aud":            "edash-client",
+			"sub":            "109876543210",
+			"email":          "Budi@Example.com",
+			"email_verified": "true",
+			"given_name":     "Budi",
+			"family_name":    "Santoso",
+			"iat":            time.Now().Unix(),
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		claims, errVerify := VerifyIDToken(context.Background(), signIDToken(t, providerKey, validClaims()))
+		if errVerify != nil {
+			t.Fatalf("expected the token to be accepted, got %v", errVerify)
+		}
+
+		if claims.Subject != "109876543210" || claims.Email != "budi@example.com" || !claims.EmailVerified {
+			t.Fatalf("unexpected claims %+v", claims)
+		}
+	})
+
+	rejected := []struct {
+		name   string
+		key    *rsa.PrivateKey
+		modify func(claims jwt.MapClaims)
+	}{
+		{name: "bad signature", key: otherKey, modify: func(claims jwt.MapClaims) {}},
+		{name: "wrong audience", key: providerKey, modify: func(claims jwt.MapClaims) { claims["aud"] = "other-client" }},
+		{name: "wrong issuer", key: providerKey, modify: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }},
+		{name: "expired", key: providerKey, modify: func(claims jwt.MapClaims) {
+			claims["iat"] = time.Now().Add(-2 * time.Hour).Unix()
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+		}},
+		{name: "missing expiration", key: providerKey, modify: func(claims jwt.MapClaims) { delete(claims, "exp") }},
+		{name: "missing subject", key: providerKey, modify: func(claims jwt.MapClaims) { delete(claims, "sub") }},
+	}
+
+	for _, test := range rejected {
+		t.Run(test.name, func(t *testing.T) {
+			claims := validClaims()
+			test.modify(claims)
+
+			_, errVerify := VerifyIDToken(context.Background(), signIDToken(t, test.key, claims))
+			if errVerify == nil {
+				t.Fatal("expected the token to be rejected")
+			}
+		})
+	}
+}
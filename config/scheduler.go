@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScheduleDaily runs the job every day at the given time of day ("15:04", in the local time zone).
+//
+// The job runs in its own goroutine. A job that panics is logged and runs again the next day, so that a failing
+// run never stops the schedule. An empty or invalid time of day falls back to defaultAt.
+func ScheduleDaily(name string, at string, defaultAt string, job func()) {
+	log := CreateLoggers(nil)
+
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		clock, _ = time.Parse("15:04", defaultAt)
+	}
+
+	go func() {
+		for {
+			now := time.Now()
+
+			next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+
+			time.Sleep(time.Until(next))
+
+			func() {
+				defer func() {
+					if errJob := recover(); errJob != nil {
+						log.Error(fmt.Sprintf("job %s failed: %v", name, errJob))
+					}
+				}()
+
+				job()
+			}()
+
+			log.Info(fmt.Sprintf("job %s finished", name))
+		}
+	}()
+}
+
+// ScheduleEvery runs the job at the given interval, or every defaultInterval when the interval is not positive.
+//
+// The job runs in its own goroutine, and the interval counts from the end of a run. A job that panics is logged
+// and runs again at the next interval.
+func ScheduleEvery(name string, interval time.Duration, defaultInterval time.Duration, job func()) {
+	log := CreateLoggers(nil)
+
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	go func() {
+		for {
+			time.Sleep(interval)
+
+			func() {
+				defer func() {
+					if errJob := recover(); errJob != nil {
+						log.Error(fmt.Sprintf("job %s failed: %v", name, errJob))
+					}
+				}()
+
+				job()
+			}()
+		}
+	}()
+}
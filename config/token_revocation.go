@@ -0,0 +1,193 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type tokenRevocationStore struct {
+	db    *sql.DB
+	mutex sync.RWMutex
+
+	// tokens maps a revoked jti to the expiration time of its token
+	tokens map[string]time.Time
+
+	// subjects maps a subject to the moment all of its tokens were revoked
+	subjects map[string]time.Time
+}
+
+var revocations = &tokenRevocationStore{
+	tokens:   map[string]time.Time{},
+	subjects: map[string]time.Time{},
+}
+
+// SetupTokenRevocation connects the token revocation store to the database.
+//
+// Revocations are persisted in the token_revocations table and cached in memory so that
+// VerifyTokenMiddleware does not hit the database on every request. The cache is loaded once here and then
+// synchronized every JWT_REVOCATION_SYNC_INTERVAL (defaults to 30 seconds), which is how revocations made by
+// other instances of the application are picked up.
+func SetupTokenRevocation(db *sql.DB) error {
+	revocations.db = db
+
+	err := revocations.sync()
+	if err != nil {
+		return err
+	}
+
+	interval := viper.GetDuration("JWT_REVOCATION_SYNC_INTERVAL")
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	go func() {
+		log := CreateLoggers(nil)
+
+		for range time.Tick(interval) {
+			if errSync := revocations.sync(); errSync != nil {
+				log.Error(errSync)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// sync replaces the cached revocations with the unexpired rows of the token_revocations table.
+//
+// The connections are opened with autocommit disabled, so the query runs in its own transaction to
+// never read from the snapshot of a previous synchronization.
+func (store *tokenRevocationStore) sync() error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := "select jti, subject, revoked_at, expires_at from token_revocations where expires_at > ?"
+
+	rows, err := tx.QueryContext(context.Background(), query, time.Now())
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	tokens := map[string]time.Time{}
+	subjects := map[string]time.Time{}
+
+	for rows.Next() {
+		var jti, subject sql.NullString
+		var revokedAt, expiresAt time.Time
+
+		err = rows.Scan(&jti, &subject, &revokedAt, &expiresAt)
+		if err != nil {
+			return err
+		}
+
+		if jti.Valid {
+			tokens[jti.String] = expiresAt
+		} else if subject.Valid && revokedAt.After(subjects[subject.String]) {
+			subjects[subject.String] = revokedAt
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// Keep the revocations made locally while the query was running
+	now := time.Now()
+	for jti, expiresAt := range store.tokens {
+		if expiresAt.After(now) {
+			tokens[jti] = expiresAt
+		}
+	}
+
+	for subject, revokedAt := range store.subjects {
+		if revokedAt.Add(AccessTokenTTL()).After(now) && revokedAt.After(subjects[subject]) {
+			subjects[subject] = revokedAt
+		}
+	}
+
+	store.tokens = tokens
+	store.subjects = subjects
+
+	return nil
+}
+
+// insert persists a revocation in the transaction, when the store is connected to a database.
+func (store *tokenRevocationStore) insert(ctx context.Context, tx *sql.Tx, jti, subject any, revokedAt, expiresAt time.Time) error {
+	if store.db == nil {
+		return nil
+	}
+
+	query := "insert into token_revocations (jti,subject,revoked_at,expires_at) values (?,?,?,?)"
+
+	_, err := tx.ExecContext(ctx, query, jti, subject, revokedAt, expiresAt)
+
+	return err
+}
+
+// RevokeToken revokes a single access token identified by its jti claim until it expires.
+//
+// The revocation is persisted in the transaction, so it is committed along with the changes revoking the session
+// of the token. It is cached right away, which at worst rejects the token early when the transaction rolls back.
+func RevokeToken(ctx context.Context, tx *sql.Tx, jti string, expiresAt time.Time) error {
+	err := revocations.insert(ctx, tx, jti, nil, time.Now(), expiresAt)
+	if err != nil {
+		return err
+	}
+
+	revocations.mutex.Lock()
+	defer revocations.mutex.Unlock()
+
+	revocations.tokens[jti] = expiresAt
+
+	return nil
+}
+
+// RevokeSubject revokes every access token of a subject, the id of a user, issued up to now.
+// The revocation is kept for AccessTokenTTL, after which those tokens have expired anyway. Like RevokeToken, it is
+// persisted in the transaction.
+func RevokeSubject(ctx context.Context, tx *sql.Tx, subject string) error {
+	now := time.Now()
+
+	err := revocations.insert(ctx, tx, nil, subject, now, now.Add(AccessTokenTTL()))
+	if err != nil {
+		return err
+	}
+
+	revocations.mutex.Lock()
+	defer revocations.mutex.Unlock()
+
+	revocations.subjects[subject] = now
+
+	return nil
+}
+
+// IsTokenRevoked reports whether a token has been revoked, either by its jti or because all the tokens of
+// its subject have been revoked after it was issued.
+//
+// The iat claim only has a precision of one second, so a token issued in the same second as a subject
+// revocation is considered revoked as well.
+func IsTokenRevoked(jti string, subject string, issuedAt time.Time) bool {
+	revocations.mutex.RLock()
+	defer revocations.mutex.RUnlock()
+
+	if _, ok := revocations.tokens[jti]; ok {
+		return true
+	}
+
+	revokedAt, ok := revocations.subjects[subject]
+
+	return ok && !issuedAt.After(revokedAt.Truncate(time.Second))
+}
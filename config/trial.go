@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"time"
+)
+
+// TrialLengthDays returns the number of days of the free trial, which starts when the onboarding of a user
+// is completed. It is read from TRIAL_LENGTH_DAYS and defaults to 14 days.
+func TrialLengthDays() int {
+	days := viper.GetInt("TRIAL_LENGTH_DAYS")
+	if days <= 0 {
+		return 14
+	}
+
+	return days
+}
+
+// TrialEnd returns when a trial started on the given date ends.
+// The trial lasts whole days, so it ends at midnight after its last day.
+func TrialEnd(startDate time.Time) time.Time {
+	year, month, day := startDate.Date()
+
+	return time.Date(year, month, day+TrialLengthDays(), 0, 0, 0, 0, startDate.Location())
+}
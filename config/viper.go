@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+
 	"github.com/spf13/viper"
 )
 
@@ -27,3 +29,26 @@ func InitConfig() {
 		return
 	}
 }
+
+// IsDevelopment reports whether the application runs in development, which is when APP_ENV is "development" or
+// "local". Tools that must not be exposed in production, such as the email previews, are only enabled then.
+func IsDevelopment() bool {
+	switch viper.GetString("APP_ENV") {
+	case "development", "local":
+		return true
+	default:
+		return false
+	}
+}
+
+// RequireSecrets checks that every secret named is set, so that the application refuses to start instead of
+// keying its HMACs with an empty secret, which anybody could reproduce.
+func RequireSecrets(names ...string) error {
+	for _, name := range names {
+		if viper.GetString(name) == "" {
+			return errors.New(name + " is not configured")
+		}
+	}
+
+	return nil
+}
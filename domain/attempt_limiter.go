@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// AttemptLimiter counts the failed attempts made on a key, such as an account or an IP address,
+	// and locks the key out for an exponentially growing duration once too many attempts failed.
+	AttemptLimiter interface {
+		// Check returns how long the key stays locked out, zero when it is not.
+		Check(ctx context.Context, key string) time.Duration
+		// Fail records a failed attempt and returns how long the key is now locked out, zero when the
+		// threshold has not been reached yet.
+		Fail(ctx context.Context, key string, threshold int) time.Duration
+		// Reset clears the failed attempts of the key.
+		Reset(ctx context.Context, key string)
+	}
+
+	clientIPContextKey struct{}
+)
+
+// NewClientIPContext returns a copy of the context carrying the IP address of the client.
+func NewClientIPContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey{}, ip)
+}
+
+// ClientIPFromContext returns the IP address of the client carried by the context.
+// It returns an empty string when the context does not carry any.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPContextKey{}).(string)
+
+	return ip
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"go-edash/enums"
 	"net/http"
+	"time"
 )
 
 type (
@@ -13,6 +14,10 @@ type (
 		Name        string
 		Description string
 		Category    enums.CompanyCategory
+		OwnerId     string
+
+		RequireTwoFactor bool
+		DeletedAt        sql.NullTime
 	}
 
 	SaveCompanyRequest struct {
@@ -27,27 +32,41 @@ type (
 		CompanyCategory    enums.CompanyCategory `validate:"required,min=1,max=250" json:"category"`
 	}
 
+	TwoFactorPolicyRequest struct {
+		RequireTwoFactor *bool `validate:"required" json:"require_two_factor"`
+	}
+
 	CompanyResponse struct {
 		CompanyName        string                `json:"company_name"`
 		CompanyDescription string                `json:"company_description"`
 		CompanyCategory    enums.CompanyCategory `json:"category"`
+		RequireTwoFactor   bool                  `json:"require_two_factor"`
 	}
 
 	CompanyRepository interface {
 		Create(ctx context.Context, tx *sql.Tx, company *Company) *Company
 		Update(ctx context.Context, tx *sql.Tx, company *Company) *Company
-		FindById(ctx context.Context, tx *sql.Tx, id string) (*Company, error)
+		UpdateTwoFactorPolicy(ctx context.Context, tx *sql.Tx, company *Company) *Company
+		UpdateOwner(ctx context.Context, tx *sql.Tx, company *Company) *Company
+		SoftDelete(ctx context.Context, tx *sql.Tx, company *Company) *Company
+		Restore(ctx context.Context, tx *sql.Tx, company *Company) *Company
+		FindCurrent(ctx context.Context, tx *sql.Tx) (*Company, error)
+		FindDeleted(ctx context.Context, tx *sql.Tx) (*Company, error)
+		FindPurgeable(ctx context.Context, tx *sql.Tx, before time.Time) []string
+		Purge(ctx context.Context, tx *sql.Tx)
 	}
 
 	CompanyService interface {
 		SaveCompany(ctx context.Context, request *SaveCompanyRequest) CompanyResponse
 		UpdateCompany(ctx context.Context, request *UpdateCompanyRequest) CompanyResponse
 		GetCompanyInformation(ctx context.Context) CompanyResponse
+		UpdateTwoFactorPolicy(ctx context.Context, request *TwoFactorPolicyRequest) CompanyResponse
 	}
 
 	CompanyHandler interface {
 		StoreCompany() http.HandlerFunc
 		UpdateCompany() http.HandlerFunc
 		GetCompany() http.HandlerFunc
+		UpdateTwoFactorPolicy() http.HandlerFunc
 	}
 )
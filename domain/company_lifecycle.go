@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+)
+
+type (
+	// OwnershipTransfer hands a company from its owner to another member. It stays pending until the new owner
+	// confirms it with the OTP sent to their email.
+	OwnershipTransfer struct {
+		Id         string
+		CompanyId  string
+		FromUserId string
+		ToUserId   string
+		ConsumedAt sql.NullTime
+	}
+
+	TransferOwnershipRequest struct {
+		MemberId string `validate:"required" json:"member_id"`
+	}
+
+	ConfirmOwnershipTransferRequest struct {
+		CompanyId string `validate:"required" json:"company_id"`
+		Otp       string `validate:"required,min=6,max=6" json:"otp"`
+	}
+
+	RestoreCompanyRequest struct {
+		CompanyId string `validate:"required" json:"company_id"`
+	}
+
+	CompanyDeletionResponse struct {
+		DeletedAt time.Time `json:"deleted_at"`
+		PurgeAt   time.Time `json:"purge_at"`
+	}
+
+	// OwnershipTransferRepository stores the ownership transfers of the company the context is scoped to.
+	OwnershipTransferRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, transfer *OwnershipTransfer) *OwnershipTransfer
+		FindPendingByUser(ctx context.Context, tx *sql.Tx, userId string) (*OwnershipTransfer, error)
+		Invalidate(ctx context.Context, tx *sql.Tx)
+	}
+
+	// CompanyLifecycleService hands companies over to another owner and closes them.
+	//
+	// A deleted company is kept for a retention window, during which its owner can restore it, before the purge
+	// job removes it for good.
+	CompanyLifecycleService interface {
+		TransferOwnership(ctx context.Context, request *TransferOwnershipRequest)
+		ConfirmOwnershipTransfer(ctx context.Context, request *ConfirmOwnershipTransferRequest) MemberResponse
+		DeleteCompany(ctx context.Context) CompanyDeletionResponse
+		RestoreCompany(ctx context.Context, request *RestoreCompanyRequest) CompanyResponse
+		PurgeDeletedCompanies(ctx context.Context)
+	}
+
+	CompanyLifecycleHandler interface {
+		TransferOwnership() http.HandlerFunc
+		ConfirmOwnershipTransfer() http.HandlerFunc
+		DeleteCompany() http.HandlerFunc
+		RestoreCompany() http.HandlerFunc
+	}
+)
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+)
+
+type (
+	EmailChange struct {
+		Id         string
+		UserId     string
+		NewEmail   string
+		ConsumedAt sql.NullTime
+	}
+
+	EmailChangeRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, change *EmailChange) *EmailChange
+		FindPendingByUser(ctx context.Context, tx *sql.Tx, userId string) (*EmailChange, error)
+		InvalidateByUser(ctx context.Context, tx *sql.Tx, userId string)
+	}
+)
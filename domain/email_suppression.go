@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/enums"
+	"net/http"
+	"time"
+)
+
+type (
+	// EmailSuppression is an email address the emails are no longer sent to, because the email provider
+	// reported that it bounced, blocked the emails or that the recipient marked them as spam.
+	EmailSuppression struct {
+		Email     string
+		Reason    enums.EmailStatus
+		Detail    string
+		CreatedAt time.Time
+	}
+
+	// EmailEvent is a delivery event reported by the email provider.
+	EmailEvent struct {
+		Email  string
+		Status enums.EmailStatus
+		Detail string
+		At     time.Time
+	}
+
+	EmailSuppressionResponse struct {
+		Email     string            `json:"email"`
+		Reason    enums.EmailStatus `json:"reason"`
+		Detail    string            `json:"detail"`
+		CreatedAt time.Time         `json:"created_at"`
+	}
+
+	EmailSuppressionRepository interface {
+		Save(ctx context.Context, tx *sql.Tx, suppression *EmailSuppression) *EmailSuppression
+		Find(ctx context.Context, tx *sql.Tx, email string) (*EmailSuppression, error)
+		FindAll(ctx context.Context, tx *sql.Tx, limit int) []*EmailSuppression
+		Delete(ctx context.Context, tx *sql.Tx, email string) bool
+	}
+
+	EmailSuppressionService interface {
+		HandleMailjetEvents(ctx context.Context, username string, password string, body []byte)
+		GetSuppressions(ctx context.Context) []EmailSuppressionResponse
+		DeleteSuppression(ctx context.Context, email string)
+	}
+
+	EmailSuppressionHandler interface {
+		MailjetWebhook() http.HandlerFunc
+		GetSuppressions() http.HandlerFunc
+		DeleteSuppression() http.HandlerFunc
+	}
+)
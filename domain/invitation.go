@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/enums"
+	"net/http"
+	"time"
+)
+
+type (
+	// Invitation invites the owner of an email to join a company. It is sent as a signed link that expires at
+	// ExpiresAt, and stays PENDING until it is accepted, declined or revoked.
+	Invitation struct {
+		Id        string
+		CompanyId string
+		Email     string
+		Role      enums.Role
+		InvitedBy string
+		Status    enums.InvitationStatus
+		ExpiresAt time.Time
+	}
+
+	InviteMemberRequest struct {
+		Email string     `validate:"required,email" json:"email"`
+		Role  enums.Role `validate:"omitempty,oneof=ADMIN USER" json:"role"`
+	}
+
+	InvitationTokenRequest struct {
+		Token string `validate:"required" json:"token"`
+	}
+
+	RegisterWithInvitationRequest struct {
+		Token                string       `validate:"required" json:"token"`
+		FirstName            string       `validate:"required,min=1,max=50" json:"first_name"`
+		LastName             string       `validate:"required,min=1,max=50" json:"last_name"`
+		Password             string       `validate:"required" json:"password"`
+		PasswordConfirmation string       `validate:"required,eqfield=Password" json:"password_confirmation"`
+		Locale               enums.Locale `validate:"omitempty,oneof=id en" json:"locale"`
+	}
+
+	InvitationResponse struct {
+		Id        string                 `json:"id"`
+		Email     string                 `json:"email"`
+		Role      enums.Role             `json:"role"`
+		Status    enums.InvitationStatus `json:"status"`
+		ExpiresAt time.Time              `json:"expires_at"`
+	}
+
+	InvitationPreviewResponse struct {
+		CompanyName string     `json:"company_name"`
+		Email       string     `json:"email"`
+		Role        enums.Role `json:"role"`
+		ExpiresAt   time.Time  `json:"expires_at"`
+	}
+
+	MemberResponse struct {
+		Id        string     `json:"id"`
+		Email     string     `json:"email"`
+		FirstName string     `json:"first_name"`
+		LastName  string     `json:"last_name"`
+		Role      enums.Role `json:"role"`
+	}
+
+	InvitationRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, invitation *Invitation) *Invitation
+		UpdateStatus(ctx context.Context, tx *sql.Tx, invitation *Invitation) *Invitation
+		FindById(ctx context.Context, tx *sql.Tx, id string) (*Invitation, error)
+		FindPending(ctx context.Context, tx *sql.Tx, at time.Time) []*Invitation
+		RevokePendingByEmail(ctx context.Context, tx *sql.Tx, email string)
+		RevokePending(ctx context.Context, tx *sql.Tx)
+	}
+
+	// TeamService manages the members of the company of the authenticated user and the invitations to join it.
+	// The invitees answer an invitation with the token of its link.
+	TeamService interface {
+		GetMembers(ctx context.Context) []MemberResponse
+		RemoveMember(ctx context.Context, id string)
+		InviteMember(ctx context.Context, request *InviteMemberRequest) InvitationResponse
+		GetInvitations(ctx context.Context) []InvitationResponse
+		RevokeInvitation(ctx context.Context, id string)
+		PreviewInvitation(ctx context.Context, token string) InvitationPreviewResponse
+		AcceptInvitation(ctx context.Context, request *InvitationTokenRequest) MemberResponse
+		RegisterWithInvitation(ctx context.Context, request *RegisterWithInvitationRequest) MemberResponse
+		DeclineInvitation(ctx context.Context, request *InvitationTokenRequest)
+	}
+
+	TeamHandler interface {
+		GetMembers() http.HandlerFunc
+		RemoveMember() http.HandlerFunc
+		InviteMember() http.HandlerFunc
+		GetInvitations() http.HandlerFunc
+		RevokeInvitation() http.HandlerFunc
+		PreviewInvitation() http.HandlerFunc
+		AcceptInvitation() http.HandlerFunc
+		RegisterWithInvitation() http.HandlerFunc
+		DeclineInvitation() http.HandlerFunc
+	}
+)
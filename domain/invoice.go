@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+)
+
+type (
+	// Invoice is issued for every paid payment. Its number is sequential within the year it was issued in.
+	Invoice struct {
+		Id          string
+		Number      string
+		CompanyId   string
+		PaymentId   string
+		PlanName    string
+		Amount      int64
+		PeriodStart time.Time
+		PeriodEnd   time.Time
+		IssuedAt    time.Time
+	}
+
+	// InvoiceDocument is an invoice rendered as a PDF file.
+	InvoiceDocument struct {
+		FileName string
+		Content  []byte
+	}
+
+	InvoiceResponse struct {
+		Id          string    `json:"id"`
+		Number      string    `json:"number"`
+		PlanName    string    `json:"plan_name"`
+		Amount      int64     `json:"amount"`
+		PeriodStart time.Time `json:"period_start"`
+		PeriodEnd   time.Time `json:"period_end"`
+		IssuedAt    time.Time `json:"issued_at"`
+	}
+
+	InvoiceRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, invoice *Invoice) *Invoice
+		NextSequence(ctx context.Context, tx *sql.Tx, year int) int64
+		FindAll(ctx context.Context, tx *sql.Tx) []*Invoice
+		FindById(ctx context.Context, tx *sql.Tx, id string) (*Invoice, error)
+	}
+
+	// InvoiceService issues the invoices of paid payments and serves them to the company they were issued to.
+	// Issue is meant for services, it runs in the transaction settling the payment.
+	InvoiceService interface {
+		Issue(ctx context.Context, tx *sql.Tx, payment *Payment, plan *Plan, subscription *Subscription) *Invoice
+		GetInvoices(ctx context.Context) []InvoiceResponse
+		DownloadInvoice(ctx context.Context, id string) InvoiceDocument
+	}
+
+	InvoiceHandler interface {
+		GetInvoices() http.HandlerFunc
+		DownloadInvoice() http.HandlerFunc
+	}
+)
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+type (
+	// Email is an email to a single recipient. Text is its plain text part and Html its optional HTML part.
+	Email struct {
+		ToEmail string
+		ToName  string
+		Subject string
+		Text    string
+		Html    string
+	}
+
+	// Mailer delivers the emails of the application.
+	Mailer interface {
+		// Send delivers the email, returning once the driver accepted or rejected it.
+		Send(ctx context.Context, email *Email) error
+	}
+)
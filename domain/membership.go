@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/enums"
+)
+
+type (
+	// Membership makes a user a member of a company, with a role of its own in every company.
+	// CompanyName is read along with the membership, for the user to choose the company to work in.
+	Membership struct {
+		UserId      string
+		CompanyId   string
+		CompanyName string
+		Role        enums.Role
+	}
+
+	// Member is a user seen as a member of a company, with the role of the user in that company.
+	Member struct {
+		UserId    string
+		Email     string
+		FirstName string
+		LastName  string
+		Role      enums.Role
+	}
+
+	SwitchCompanyRequest struct {
+		CompanyId string `validate:"required" json:"company_id"`
+	}
+
+	MembershipResponse struct {
+		CompanyId   string     `json:"company_id"`
+		CompanyName string     `json:"company_name"`
+		Role        enums.Role `json:"role"`
+		Active      bool       `json:"active"`
+	}
+
+	// MembershipRepository links users and companies. Create, Find and FindAllByUser work across companies, for
+	// the user to join and switch companies. The other methods are scoped to the company of the context.
+	MembershipRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, membership *Membership) *Membership
+		Find(ctx context.Context, tx *sql.Tx, userId string, companyId string) (*Membership, error)
+		FindAllByUser(ctx context.Context, tx *sql.Tx, userId string) []*Membership
+		FindMember(ctx context.Context, tx *sql.Tx, userId string) (*Membership, error)
+		FindMembers(ctx context.Context, tx *sql.Tx) []*Member
+		UpdateRole(ctx context.Context, tx *sql.Tx, membership *Membership) *Membership
+		Delete(ctx context.Context, tx *sql.Tx, userId string)
+	}
+)
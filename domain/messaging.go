@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"go-edash/enums"
+)
+
+type (
+	// Message is a short text message to a phone number in the E.164 format.
+	//
+	// Text is the full message, for the channels sending free text. Channels that can only start a conversation
+	// with a pre-approved template, like WhatsApp, send the Otp through their template in the Locale instead.
+	Message struct {
+		ToPhone string
+		Locale  enums.Locale
+		Text    string
+		Otp     string
+	}
+
+	// MessageChannel delivers the short text messages of the application through a single channel.
+	MessageChannel interface {
+		// Send delivers the message, returning once the provider accepted or rejected it.
+		Send(ctx context.Context, message *Message) error
+	}
+
+	// Messenger delivers the short text messages of the application through the channel chosen by the user.
+	Messenger interface {
+		Send(ctx context.Context, channel enums.MessageChannel, message *Message) error
+	}
+)
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/enums"
+	"time"
+)
+
+type (
+	Otp struct {
+		Id         string
+		Purpose    enums.OtpPurpose
+		Target     string
+		CodeHash   string
+		ExpiresAt  time.Time
+		Attempts   int
+		ConsumedAt sql.NullTime
+	}
+
+	OtpRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, otp *Otp) *Otp
+		FindPending(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string) (*Otp, error)
+		IncrementAttempts(ctx context.Context, tx *sql.Tx, id string)
+		Consume(ctx context.Context, tx *sql.Tx, id string) bool
+		Invalidate(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string)
+	}
+
+	OtpService interface {
+		Issue(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string) (string, time.Time)
+		Verify(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string, code string)
+		Invalidate(ctx context.Context, tx *sql.Tx, purpose enums.OtpPurpose, target string)
+	}
+)
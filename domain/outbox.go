@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/enums"
+	"net/http"
+	"time"
+)
+
+type (
+	// OutboxMessage is an email waiting in the outbox, written in the transaction of the change it tells about.
+	// The dispatcher sends it once that transaction is committed, and retries it until it is SENT or DEAD.
+	// An email to a suppressed address is never sent and ends SUPPRESSED.
+	//
+	// The outbox holds the short text messages to phone numbers as well. Those have a Channel and their Message is
+	// sent through the Messenger instead of the Email.
+	OutboxMessage struct {
+		Id            string
+		Email         Email
+		Channel       enums.MessageChannel
+		Message       Message
+		Status        enums.OutboxStatus
+		Attempts      int
+		NextAttemptAt time.Time
+		LastError     string
+		CreatedAt     time.Time
+		SentAt        sql.NullTime
+	}
+
+	OutboxMessagesRequest struct {
+		Status enums.OutboxStatus `validate:"omitempty,oneof=PENDING SENT DEAD SUPPRESSED"`
+	}
+
+	OutboxMessageResponse struct {
+		Id            string               `json:"id"`
+		ToEmail       string               `json:"to_email,omitempty"`
+		ToPhone       string               `json:"to_phone,omitempty"`
+		Channel       enums.MessageChannel `json:"channel,omitempty"`
+		Subject       string               `json:"subject"`
+		Status        enums.OutboxStatus   `json:"status"`
+		Attempts      int                  `json:"attempts"`
+		NextAttemptAt time.Time            `json:"next_attempt_at"`
+		LastError     string               `json:"last_error"`
+		CreatedAt     time.Time            `json:"created_at"`
+	}
+
+	OutboxRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, message *OutboxMessage) *OutboxMessage
+		Update(ctx context.Context, tx *sql.Tx, message *OutboxMessage) *OutboxMessage
+		FindById(ctx context.Context, tx *sql.Tx, id string) (*OutboxMessage, error)
+		FindAllByStatus(ctx context.Context, tx *sql.Tx, status enums.OutboxStatus, limit int) []*OutboxMessage
+		ClaimDue(ctx context.Context, tx *sql.Tx, at time.Time, limit int) []*OutboxMessage
+		DeleteFinishedBefore(ctx context.Context, tx *sql.Tx, before time.Time) int64
+	}
+
+	// OutboxService queues the emails of the application and delivers them through the Mailer, and the short text
+	// messages through the Messenger.
+	OutboxService interface {
+		Enqueue(ctx context.Context, tx *sql.Tx, email *Email)
+		EnqueueTemplate(ctx context.Context, tx *sql.Tx, email string, name string, locale enums.Locale,
+			template enums.EmailTemplate, data map[string]any)
+		EnqueueMessage(ctx context.Context, tx *sql.Tx, channel enums.MessageChannel, message *Message)
+		Dispatch(ctx context.Context)
+		PurgeMessages(ctx context.Context)
+		GetMessages(ctx context.Context, request *OutboxMessagesRequest) []OutboxMessageResponse
+		RetryMessage(ctx context.Context, id string) OutboxMessageResponse
+	}
+
+	OutboxHandler interface {
+		GetMessages() http.HandlerFunc
+		RetryMessage() http.HandlerFunc
+	}
+)
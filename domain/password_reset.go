@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type (
+	PasswordReset struct {
+		Id        string
+		UserId    string
+		TokenHash string
+		ExpiresAt time.Time
+		UsedAt    sql.NullTime
+	}
+
+	PasswordResetRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, reset *PasswordReset) *PasswordReset
+		FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (*PasswordReset, error)
+		InvalidateByUser(ctx context.Context, tx *sql.Tx, userId string)
+	}
+)
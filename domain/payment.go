@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/enums"
+	"net/http"
+)
+
+type (
+	// Payment is a charge of the payment provider for a plan. Its id is the order id known by the provider.
+	Payment struct {
+		Id          string
+		CompanyId   string
+		PlanId      string
+		Amount      int64
+		Status      enums.PaymentStatus
+		Reference   string
+		RedirectUrl string
+		PaidAt      sql.NullTime
+	}
+
+	CheckoutRequest struct {
+		PlanCode string `validate:"required" json:"plan_code"`
+	}
+
+	CheckoutResponse struct {
+		OrderId     string `json:"order_id"`
+		Amount      int64  `json:"amount"`
+		RedirectUrl string `json:"redirect_url"`
+	}
+
+	PaymentRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, payment *Payment) *Payment
+		Update(ctx context.Context, tx *sql.Tx, payment *Payment) *Payment
+		FindById(ctx context.Context, tx *sql.Tx, id string) (*Payment, error)
+		RecordNotification(ctx context.Context, tx *sql.Tx, key string) bool
+	}
+
+	PaymentService interface {
+		Checkout(ctx context.Context, request *CheckoutRequest) CheckoutResponse
+		HandleNotification(ctx context.Context, body []byte)
+	}
+
+	PaymentHandler interface {
+		Checkout() http.HandlerFunc
+		Webhook() http.HandlerFunc
+	}
+)
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"go-edash/enums"
+)
+
+type (
+	// PaymentCharge is an amount, in rupiah, to be collected by the payment provider.
+	PaymentCharge struct {
+		OrderId       string
+		Amount        int64
+		ItemName      string
+		CustomerName  string
+		CustomerEmail string
+	}
+
+	// PaymentCheckout is where the customer pays a charge.
+	PaymentCheckout struct {
+		Reference   string
+		RedirectUrl string
+	}
+
+	// PaymentNotification is a verified callback of the payment provider about a charge.
+	PaymentNotification struct {
+		OrderId       string
+		TransactionId string
+		Status        enums.PaymentStatus
+		Amount        int64
+	}
+
+	// PaymentRejectedError is returned by a PaymentProvider that refused to create a charge. Any other error of
+	// CreateCharge leaves it unknown whether the charge has been created.
+	PaymentRejectedError struct {
+		Message string
+	}
+
+	// PaymentProvider collects the payments of the customers.
+	PaymentProvider interface {
+		// CreateCharge creates a charge and returns where the customer pays it.
+		CreateCharge(ctx context.Context, charge *PaymentCharge) (*PaymentCheckout, error)
+		// ParseNotification verifies the signature of a callback and returns its content.
+		ParseNotification(body []byte) (*PaymentNotification, error)
+	}
+)
+
+func (err PaymentRejectedError) Error() string {
+	return err.Message
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"go-edash/enums"
+	"time"
+)
+
+type (
+	PhoneVerificationRequest struct {
+		PhoneNumber string               `validate:"required,max=30" json:"phone_number"`
+		Channel     enums.MessageChannel `validate:"omitempty,oneof=SMS WHATSAPP" json:"channel"`
+	}
+
+	ConfirmPhoneVerificationRequest struct {
+		Otp string `validate:"required,min=6,max=6" json:"otp"`
+	}
+
+	PhoneVerificationResponse struct {
+		PhoneNumber string               `json:"phone_number"`
+		Channel     enums.MessageChannel `json:"channel"`
+		ExpiresAt   time.Time            `json:"expires_at"`
+	}
+)
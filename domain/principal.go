@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"time"
+)
+
+type (
+	// Principal is the authenticated caller of a request, as described by its access token.
+	Principal struct {
+		UserId    string
+		Email     string
+		Role      enums.Role
+		CompanyId string
+		SessionId string
+		TokenId   string
+		ExpiresAt time.Time
+
+		RegistrationStep enums.RegistrationStep
+		TrialEndsAt      time.Time
+	}
+
+	principalContextKey struct{}
+)
+
+// NewPrincipalContext returns a copy of the context carrying the principal.
+func NewPrincipalContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal carried by the context, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+
+	return principal, ok && principal != nil
+}
+
+// MustPrincipal returns the principal carried by the context.
+// It panics with an UnauthorizedError when the request has not been authenticated.
+func MustPrincipal(ctx context.Context) *Principal {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		panic(exceptions.NewUnauthorizedError("unauthenticated request"))
+	}
+
+	return principal
+}
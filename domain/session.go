@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type (
+	Session struct {
+		Id        string
+		UserId    string
+		FamilyId  string
+		TokenHash string
+		ExpiresAt time.Time
+		RotatedAt sql.NullTime
+		RevokedAt sql.NullTime
+	}
+
+	SessionRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, session *Session) *Session
+		FindByTokenHash(ctx context.Context, tx *sql.Tx, tokenHash string) (*Session, error)
+		MarkRotated(ctx context.Context, tx *sql.Tx, id string)
+		MarkFamilyRotated(ctx context.Context, tx *sql.Tx, familyId string)
+		RevokeFamily(ctx context.Context, tx *sql.Tx, familyId string)
+		RevokeByUser(ctx context.Context, tx *sql.Tx, userId string)
+	}
+)
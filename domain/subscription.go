@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/enums"
+	"net/http"
+	"time"
+)
+
+type (
+	// Plan is an entry of the plans catalog. A limit left null is unlimited.
+	Plan struct {
+		Id            string
+		Code          string
+		Name          string
+		Price         int64
+		MaxMembers    sql.NullInt64
+		MaxDashboards sql.NullInt64
+		MaxApiCalls   sql.NullInt64
+	}
+
+	// Subscription is the plan a company paid for, over a period.
+	// A canceled subscription stays usable until the end of its period, but is not renewed.
+	Subscription struct {
+		Id          string
+		CompanyId   string
+		PlanId      string
+		Status      enums.SubscriptionStatus
+		PeriodStart time.Time
+		PeriodEnd   time.Time
+		CanceledAt  sql.NullTime
+	}
+
+	// Entitlements is what a company is currently entitled to, through its subscription or its free trial.
+	Entitlements struct {
+		Plan        *Plan
+		Trial       bool
+		ActiveUntil time.Time
+	}
+
+	PlanLimitsResponse struct {
+		MaxMembers    *int64 `json:"max_members"`
+		MaxDashboards *int64 `json:"max_dashboards"`
+		MaxApiCalls   *int64 `json:"max_api_calls"`
+	}
+
+	PlanResponse struct {
+		Code   string             `json:"code"`
+		Name   string             `json:"name"`
+		Price  int64              `json:"price"`
+		Limits PlanLimitsResponse `json:"limits"`
+	}
+
+	SubscriptionResponse struct {
+		Plan        PlanResponse `json:"plan"`
+		Trial       bool         `json:"trial"`
+		ActiveUntil time.Time    `json:"active_until"`
+		SeatsUsed   int64        `json:"seats_used"`
+	}
+
+	PlanRepository interface {
+		FindAll(ctx context.Context, tx *sql.Tx) []*Plan
+		FindById(ctx context.Context, tx *sql.Tx, id string) (*Plan, error)
+		FindByCode(ctx context.Context, tx *sql.Tx, code string) (*Plan, error)
+	}
+
+	SubscriptionRepository interface {
+		Create(ctx context.Context, tx *sql.Tx, subscription *Subscription) *Subscription
+		Expire(ctx context.Context, tx *sql.Tx)
+		FindCurrent(ctx context.Context, tx *sql.Tx, at time.Time) (*Subscription, error)
+		FindLatest(ctx context.Context, tx *sql.Tx, at time.Time) (*Subscription, error)
+		FindTrialStart(ctx context.Context, tx *sql.Tx) (time.Time, error)
+		CountMembers(ctx context.Context, tx *sql.Tx) int64
+	}
+
+	// EntitlementService answers what the company the context is scoped to may use. The methods taking a
+	// transaction are meant for services, HasPaidAccess is meant for middleware.
+	EntitlementService interface {
+		Resolve(ctx context.Context, tx *sql.Tx) (*Entitlements, bool)
+		HasPaidAccess(ctx context.Context) bool
+		EnsureCapacity(ctx context.Context, tx *sql.Tx, limit enums.PlanLimit, used int64)
+		EnsureSeatAvailable(ctx context.Context, tx *sql.Tx)
+	}
+
+	SubscriptionService interface {
+		GetPlans(ctx context.Context) []PlanResponse
+		GetSubscription(ctx context.Context) SubscriptionResponse
+	}
+
+	SubscriptionHandler interface {
+		GetPlans() http.HandlerFunc
+		GetSubscription() http.HandlerFunc
+	}
+)
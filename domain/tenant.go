@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/exceptions"
+)
+
+type tenantContextKey struct{}
+
+// NewTenantContext returns a copy of the context scoped to a company.
+//
+// Repositories of the data owned by companies only read and write the rows of the company the context is scoped
+// to. Requests are scoped to the active company of their principal; services working outside of a request, such
+// as payment callbacks, scope the context themselves once they know which company they work for.
+func NewTenantContext(ctx context.Context, companyId string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, companyId)
+}
+
+// TenantFromContext returns the id of the company the context is scoped to, if any.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	companyId, ok := ctx.Value(tenantContextKey{}).(string)
+
+	return companyId, ok && companyId != ""
+}
+
+// MustTenant returns the id of the company the context is scoped to.
+// It panics with a ForbiddenError when the context is not scoped to any company, so that a query is never run
+// across every company.
+func MustTenant(ctx context.Context) string {
+	companyId, ok := TenantFromContext(ctx)
+	if !ok {
+		panic(exceptions.NewForbiddenError("no active company"))
+	}
+
+	return companyId
+}
+
+// MustAffectTenant checks that a write constrained by the company the context is scoped to has found its row.
+// A write missing its row targets a row of another company, or no row at all, so it panics with a NotFoundError
+// which does not tell the two apart.
+func MustAffectTenant(result sql.Result, entity string) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+
+	if affected == 0 {
+		panic(exceptions.NewNotFoundError(entity + " not found"))
+	}
+}
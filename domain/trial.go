@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type (
+	TrialRepository interface {
+		ExpireTrials(ctx context.Context, tx *sql.Tx, startedOnOrBefore time.Time) int64
+		FindActiveTrials(ctx context.Context, tx *sql.Tx, startedOnOrBefore time.Time) []*User
+		MarkReminderSent(ctx context.Context, tx *sql.Tx, userId string, reminder string) bool
+	}
+
+	TrialService interface {
+		RunDailyJob(ctx context.Context)
+	}
+)
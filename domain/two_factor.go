@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+)
+
+type (
+	TwoFactor struct {
+		UserId       string
+		Secret       string
+		EnabledAt    sql.NullTime
+		LastUsedStep int64
+	}
+
+	TwoFactorEnrollmentResponse struct {
+		Secret string `json:"secret"`
+		Uri    string `json:"uri"`
+	}
+
+	RecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	TwoFactorLoginRequest struct {
+		TwoFactorToken string `validate:"required" json:"two_factor_token"`
+		Code           string `validate:"required_without=RecoveryCode,omitempty,len=6" json:"code"`
+		RecoveryCode   string `validate:"required_without=Code" json:"recovery_code"`
+	}
+
+	TwoFactorChallengeRequest struct {
+		TwoFactorToken string `validate:"required" json:"two_factor_token"`
+	}
+
+	TwoFactorActivationRequest struct {
+		TwoFactorToken string `validate:"required" json:"two_factor_token"`
+		Code           string `validate:"required,len=6" json:"code"`
+	}
+
+	TwoFactorCodeRequest struct {
+		Code string `validate:"required,len=6" json:"code"`
+	}
+
+	DisableTwoFactorRequest struct {
+		Password string `validate:"required" json:"password"`
+		Code     string `validate:"required,len=6" json:"code"`
+	}
+
+	TwoFactorRepository interface {
+		Save(ctx context.Context, tx *sql.Tx, twoFactor *TwoFactor) *TwoFactor
+		FindByUser(ctx context.Context, tx *sql.Tx, userId string) (*TwoFactor, error)
+		Enable(ctx context.Context, tx *sql.Tx, twoFactor *TwoFactor) *TwoFactor
+		UpdateLastUsedStep(ctx context.Context, tx *sql.Tx, twoFactor *TwoFactor) *TwoFactor
+		Delete(ctx context.Context, tx *sql.Tx, userId string)
+		ReplaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userId string, codeHashes []string)
+		ConsumeRecoveryCode(ctx context.Context, tx *sql.Tx, userId string, codeHash string) bool
+		IsRequiredByCompany(ctx context.Context, tx *sql.Tx, companyId string) bool
+	}
+)
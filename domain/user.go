@@ -13,44 +13,113 @@ type (
 		Email            string
 		Password         string
 		PhoneNumber      string
+		PhoneVerifiedAt  sql.NullTime
 		FirstName        string
 		LastName         string
 		Role             enums.Role
 		Provider         string
-		ProviderId       int8
-		Otp              string
-		OtpExpiredTime   string
-		RegistrationStep int8
+		ProviderId       string
+		RegistrationStep enums.RegistrationStep
 		StatusTrial      bool
-		TrialStartDate   string
+		TrialStartDate   sql.NullTime
 		CompanyId        string
+		Locale           enums.Locale
+		// EmailStatus is read from the suppression list of the emails, it is not stored with the user.
+		EmailStatus enums.EmailStatus
+
+		// PendingPhoneNumber is the number waiting for its verification code, which replaces PhoneNumber once the
+		// code is confirmed.
+		PendingPhoneNumber string
 	}
 
 	UserResponse struct {
-		Email     string `json:"email"`
-		FirstName string `json:"first_name"`
-		LastName  string `json:"last_name"`
+		Email         string            `json:"email"`
+		FirstName     string            `json:"first_name"`
+		LastName      string            `json:"last_name"`
+		Locale        enums.Locale      `json:"locale"`
+		EmailStatus   enums.EmailStatus `json:"email_status"`
+		PhoneNumber   string            `json:"phone_number,omitempty"`
+		PhoneVerified bool              `json:"phone_verified"`
+
+		PendingPhoneNumber string `json:"pending_phone_number,omitempty"`
 	}
 
 	AuthResponse struct {
-		Email     string `json:"email"`
-		FirstName string `json:"first_name"`
-		LastName  string `json:"last_name"`
-		Token     string `json:"token"`
+		Email        string `json:"email"`
+		FirstName    string `json:"first_name"`
+		LastName     string `json:"last_name"`
+		Token        string `json:"token,omitempty"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		ExpiresIn    int64  `json:"expires_in,omitempty"`
+
+		TwoFactorRequired           bool     `json:"two_factor_required,omitempty"`
+		TwoFactorEnrollmentRequired bool     `json:"two_factor_enrollment_required,omitempty"`
+		TwoFactorToken              string   `json:"two_factor_token,omitempty"`
+		RecoveryCodes               []string `json:"recovery_codes,omitempty"`
 	}
 
 	RegisterBasicWithoutSSORequest struct {
-		FirstName            string `validate:"required,min=1,max=50" json:"first_name"`
-		LastName             string `validate:"required,min=1,max=50" json:"last_name"`
-		Email                string `validate:"required,email" json:"email"`
+		FirstName            string       `validate:"required,min=1,max=50" json:"first_name"`
+		LastName             string       `validate:"required,min=1,max=50" json:"last_name"`
+		Email                string       `validate:"required,email" json:"email"`
+		Password             string       `validate:"required" json:"password"`
+		PasswordConfirmation string       `validate:"required,eqfield=Password" json:"password_confirmation"`
+		Locale               enums.Locale `validate:"omitempty,oneof=id en" json:"locale"`
+	}
+
+	RegisterBasicWithSSORequest struct {
+		IdToken   string       `validate:"required" json:"id_token"`
+		FirstName string       `validate:"omitempty,max=50" json:"first_name"`
+		LastName  string       `validate:"omitempty,max=50" json:"last_name"`
+		Locale    enums.Locale `validate:"omitempty,oneof=id en" json:"locale"`
+	}
+
+	UpdateLocaleRequest struct {
+		Locale enums.Locale `validate:"required,oneof=id en" json:"locale"`
+	}
+
+	LoginWithSSORequest struct {
+		IdToken string `validate:"required" json:"id_token"`
+	}
+
+	LoginRequest struct {
+		Email    string `validate:"required,email" json:"email"`
+		Password string `validate:"required" json:"password"`
+	}
+
+	RefreshTokenRequest struct {
+		RefreshToken string `validate:"required" json:"refresh_token"`
+	}
+
+	ForgotPasswordRequest struct {
+		Email string `validate:"required,email" json:"email"`
+	}
+
+	ResetPasswordRequest struct {
+		Token                string `validate:"required" json:"token"`
 		Password             string `validate:"required" json:"password"`
 		PasswordConfirmation string `validate:"required,eqfield=Password" json:"password_confirmation"`
 	}
 
-	RegisterBasicWithSSORequest struct {
-		FirstName string `validate:"required,min=1,max=50" json:"first_name"`
-		LastName  string `validate:"required,min=1,max=50" json:"last_name"`
-		Email     string `validate:"required,email" json:"email"`
+	ChangePasswordRequest struct {
+		CurrentPassword      string `validate:"required" json:"current_password"`
+		Password             string `validate:"required" json:"password"`
+		PasswordConfirmation string `validate:"required,eqfield=Password" json:"password_confirmation"`
+	}
+
+	ChangeEmailRequest struct {
+		NewEmail string `validate:"required,email" json:"new_email"`
+		Password string `validate:"required" json:"password"`
+	}
+
+	ConfirmEmailChangeRequest struct {
+		Otp string `validate:"required,min=6,max=6" json:"otp"`
+	}
+
+	OnboardingResponse struct {
+		Step        string            `json:"step"`
+		NextStep    string            `json:"next_step,omitempty"`
+		EmailStatus enums.EmailStatus `json:"email_status"`
 	}
 
 	VerificationOTPRequest struct {
@@ -65,21 +134,70 @@ type (
 	UserRepository interface {
 		Create(ctx context.Context, tx *sql.Tx, user *User) *User
 		Update(ctx context.Context, tx *sql.Tx, user *User) *User
+		UpdateEmail(ctx context.Context, tx *sql.Tx, user *User, email string) *User
 		FindByEmail(ctx context.Context, tx *sql.Tx, email string) (*User, error)
+		FindById(ctx context.Context, tx *sql.Tx, id string) (*User, error)
+		FindByProvider(ctx context.Context, tx *sql.Tx, provider string, providerId string) (*User, error)
 	}
 
 	UserService interface {
 		SaveRegisterBasicWithoutSSO(ctx context.Context, request *RegisterBasicWithoutSSORequest) AuthResponse
 		SaveRegisterBasicWithSSO(ctx context.Context, request *RegisterBasicWithSSORequest) AuthResponse
+		Login(ctx context.Context, request *LoginRequest) AuthResponse
+		LoginWithSSO(ctx context.Context, request *LoginWithSSORequest) AuthResponse
+		RefreshToken(ctx context.Context, request *RefreshTokenRequest) AuthResponse
+		Logout(ctx context.Context)
+		LogoutAllDevices(ctx context.Context)
+		RequestPasswordReset(ctx context.Context, request *ForgotPasswordRequest)
+		ResetPassword(ctx context.Context, request *ResetPasswordRequest)
+		ChangePassword(ctx context.Context, request *ChangePasswordRequest)
+		RequestEmailChange(ctx context.Context, request *ChangeEmailRequest)
+		ConfirmEmailChange(ctx context.Context, request *ConfirmEmailChangeRequest)
+		UpdateLocale(ctx context.Context, request *UpdateLocaleRequest) UserResponse
+		RequestPhoneVerification(ctx context.Context, request *PhoneVerificationRequest) PhoneVerificationResponse
+		ConfirmPhoneVerification(ctx context.Context, request *ConfirmPhoneVerificationRequest) UserResponse
+		LoginWithTwoFactor(ctx context.Context, request *TwoFactorLoginRequest) AuthResponse
+		EnrollTwoFactorAtLogin(ctx context.Context, request *TwoFactorChallengeRequest) TwoFactorEnrollmentResponse
+		ActivateTwoFactorAtLogin(ctx context.Context, request *TwoFactorActivationRequest) AuthResponse
+		EnrollTwoFactor(ctx context.Context) TwoFactorEnrollmentResponse
+		ActivateTwoFactor(ctx context.Context, request *TwoFactorCodeRequest) RecoveryCodesResponse
+		DisableTwoFactor(ctx context.Context, request *DisableTwoFactorRequest)
 		GetByEmail(ctx context.Context, email string) UserResponse
-		CheckVerificationOTP(ctx context.Context, request *VerificationOTPRequest)
+		GetOnboarding(ctx context.Context) OnboardingResponse
+		CompleteOnboarding(ctx context.Context) AuthResponse
+		GetCompanies(ctx context.Context) []MembershipResponse
+		SwitchCompany(ctx context.Context, request *SwitchCompanyRequest) AuthResponse
+		CheckVerificationOTP(ctx context.Context, request *VerificationOTPRequest) AuthResponse
 		GenerateNewOTP(ctx context.Context, request *GenerateOTPRequest)
 	}
 
 	UserHandler interface {
 		RegisterBasicWithoutSSO() http.HandlerFunc
 		RegisterBasicWithSSO() http.HandlerFunc
+		Login() http.HandlerFunc
+		LoginWithSSO() http.HandlerFunc
+		RefreshToken() http.HandlerFunc
+		Logout() http.HandlerFunc
+		LogoutAllDevices() http.HandlerFunc
+		ForgotPassword() http.HandlerFunc
+		ResetPassword() http.HandlerFunc
+		ChangePassword() http.HandlerFunc
+		ChangeEmail() http.HandlerFunc
+		ConfirmEmailChange() http.HandlerFunc
+		UpdateLocale() http.HandlerFunc
+		RequestPhoneVerification() http.HandlerFunc
+		ConfirmPhoneVerification() http.HandlerFunc
+		LoginWithTwoFactor() http.HandlerFunc
+		EnrollTwoFactorAtLogin() http.HandlerFunc
+		ActivateTwoFactorAtLogin() http.HandlerFunc
+		EnrollTwoFactor() http.HandlerFunc
+		ActivateTwoFactor() http.HandlerFunc
+		DisableTwoFactor() http.HandlerFunc
 		GetByEmail() http.HandlerFunc
+		GetOnboarding() http.HandlerFunc
+		CompleteOnboarding() http.HandlerFunc
+		GetCompanies() http.HandlerFunc
+		SwitchCompany() http.HandlerFunc
 		VerificationOTP() http.HandlerFunc
 		GenerateOTP() http.HandlerFunc
 	}
@@ -0,0 +1,11 @@
+package enums
+
+// EmailStatus is the delivery status of an email address, as reported by the email provider.
+type EmailStatus string
+
+const (
+	EMAIL_DELIVERABLE EmailStatus = "DELIVERABLE"
+	EMAIL_BOUNCED     EmailStatus = "BOUNCED"
+	EMAIL_BLOCKED     EmailStatus = "BLOCKED"
+	EMAIL_SPAM        EmailStatus = "SPAM"
+)
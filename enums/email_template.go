@@ -0,0 +1,15 @@
+package enums
+
+type EmailTemplate string
+
+const (
+	EMAIL_REGISTRATION_OTP      EmailTemplate = "registration_otp"
+	EMAIL_PASSWORD_RESET        EmailTemplate = "password_reset"
+	EMAIL_PASSWORD_CHANGED      EmailTemplate = "password_changed"
+	EMAIL_EMAIL_CHANGE_OTP      EmailTemplate = "email_change_otp"
+	EMAIL_EMAIL_CHANGED         EmailTemplate = "email_changed"
+	EMAIL_TRIAL_REMINDER        EmailTemplate = "trial_reminder"
+	EMAIL_INVITATION            EmailTemplate = "invitation"
+	EMAIL_OWNERSHIP_TRANSFER    EmailTemplate = "ownership_transfer"
+	EMAIL_OWNERSHIP_TRANSFERRED EmailTemplate = "ownership_transferred"
+)
@@ -0,0 +1,10 @@
+package enums
+
+type InvitationStatus string
+
+const (
+	INVITATION_PENDING  InvitationStatus = "PENDING"
+	INVITATION_ACCEPTED InvitationStatus = "ACCEPTED"
+	INVITATION_DECLINED InvitationStatus = "DECLINED"
+	INVITATION_REVOKED  InvitationStatus = "REVOKED"
+)
@@ -0,0 +1,11 @@
+package enums
+
+type Locale string
+
+const (
+	LOCALE_ID Locale = "id"
+	LOCALE_EN Locale = "en"
+
+	// DEFAULT_LOCALE is the locale of the users who have not chosen one.
+	DEFAULT_LOCALE = LOCALE_ID
+)
@@ -0,0 +1,9 @@
+package enums
+
+// MessageChannel is a channel the short text messages to the phone of a user are sent through.
+type MessageChannel string
+
+const (
+	CHANNEL_SMS      MessageChannel = "SMS"
+	CHANNEL_WHATSAPP MessageChannel = "WHATSAPP"
+)
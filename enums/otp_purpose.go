@@ -0,0 +1,10 @@
+package enums
+
+type OtpPurpose string
+
+const (
+	OTP_REGISTRATION       OtpPurpose = "REGISTRATION"
+	OTP_EMAIL_CHANGE       OtpPurpose = "EMAIL_CHANGE"
+	OTP_OWNERSHIP_TRANSFER OtpPurpose = "OWNERSHIP_TRANSFER"
+	OTP_PHONE_VERIFICATION OtpPurpose = "PHONE_VERIFICATION"
+)
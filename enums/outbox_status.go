@@ -0,0 +1,10 @@
+package enums
+
+type OutboxStatus string
+
+const (
+	OUTBOX_PENDING    OutboxStatus = "PENDING"
+	OUTBOX_SENT       OutboxStatus = "SENT"
+	OUTBOX_DEAD       OutboxStatus = "DEAD"
+	OUTBOX_SUPPRESSED OutboxStatus = "SUPPRESSED"
+)
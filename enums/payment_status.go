@@ -0,0 +1,9 @@
+package enums
+
+type PaymentStatus string
+
+const (
+	PAYMENT_PENDING PaymentStatus = "PENDING"
+	PAYMENT_PAID    PaymentStatus = "PAID"
+	PAYMENT_FAILED  PaymentStatus = "FAILED"
+)
@@ -0,0 +1,38 @@
+package enums
+
+type Permission string
+
+const (
+	USER_READ      Permission = "USER_READ"
+	COMPANY_READ   Permission = "COMPANY_READ"
+	COMPANY_CREATE Permission = "COMPANY_CREATE"
+	COMPANY_UPDATE Permission = "COMPANY_UPDATE"
+
+	SUBSCRIPTION_READ   Permission = "SUBSCRIPTION_READ"
+	SUBSCRIPTION_MANAGE Permission = "SUBSCRIPTION_MANAGE"
+
+	MEMBER_READ   Permission = "MEMBER_READ"
+	MEMBER_MANAGE Permission = "MEMBER_MANAGE"
+
+	OUTBOX_MANAGE Permission = "OUTBOX_MANAGE"
+)
+
+// rolePermissions is the permission matrix, listing what every role is allowed to do.
+var rolePermissions = map[Role][]Permission{
+	SUPERADMIN: {USER_READ, COMPANY_READ, COMPANY_CREATE, COMPANY_UPDATE, SUBSCRIPTION_READ, SUBSCRIPTION_MANAGE,
+		MEMBER_READ, MEMBER_MANAGE, OUTBOX_MANAGE},
+	ADMIN: {USER_READ, COMPANY_READ, COMPANY_CREATE, COMPANY_UPDATE, SUBSCRIPTION_READ, SUBSCRIPTION_MANAGE,
+		MEMBER_READ, MEMBER_MANAGE},
+	USER: {USER_READ, COMPANY_READ, MEMBER_READ},
+}
+
+// HasPermission reports whether the permission matrix grants the permission to the role.
+func (role Role) HasPermission(permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,10 @@
+package enums
+
+// PlanLimit is a resource whose usage is limited by the plan of a company.
+type PlanLimit string
+
+const (
+	MEMBER_SEATS PlanLimit = "MEMBER_SEATS"
+	DASHBOARDS   PlanLimit = "DASHBOARDS"
+	API_CALLS    PlanLimit = "API_CALLS"
+)
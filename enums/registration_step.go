@@ -0,0 +1,42 @@
+package enums
+
+// RegistrationStep is the onboarding state of a user.
+// A user moves through the steps in order: REGISTERED, EMAIL_VERIFIED, COMPANY_CREATED and finally DONE.
+type RegistrationStep int8
+
+const (
+	REGISTERED RegistrationStep = iota
+	EMAIL_VERIFIED
+	COMPANY_CREATED
+	DONE
+)
+
+var registrationStepNames = map[RegistrationStep]string{
+	REGISTERED:      "REGISTERED",
+	EMAIL_VERIFIED:  "EMAIL_VERIFIED",
+	COMPANY_CREATED: "COMPANY_CREATED",
+	DONE:            "DONE",
+}
+
+func (step RegistrationStep) String() string {
+	name, ok := registrationStepNames[step]
+	if !ok {
+		return "UNKNOWN"
+	}
+
+	return name
+}
+
+// Next returns the step following this one, and false when the onboarding is already done.
+func (step RegistrationStep) Next() (RegistrationStep, bool) {
+	if step >= DONE {
+		return step, false
+	}
+
+	return step + 1, true
+}
+
+// Reached reports whether the onboarding has reached the given step.
+func (step RegistrationStep) Reached(required RegistrationStep) bool {
+	return step >= required
+}
@@ -0,0 +1,9 @@
+package enums
+
+type SubscriptionStatus string
+
+const (
+	SUBSCRIPTION_ACTIVE   SubscriptionStatus = "ACTIVE"
+	SUBSCRIPTION_CANCELED SubscriptionStatus = "CANCELED"
+	SUBSCRIPTION_EXPIRED  SubscriptionStatus = "EXPIRED"
+)
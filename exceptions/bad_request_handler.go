@@ -15,8 +15,8 @@ type FormatError struct {
 }
 
 // convertTagToMessage converts a validator.FieldError's tag into a human-readable error message.
-// It supports the following tags: "required", "email", "min", and "max".
-// For "min" and "max" tags, it includes the field's parameter in the error message.
+// It supports the following tags: "required", "email", "min", "max", "len", "eqfield" and "required_without".
+// For the tags taking a parameter, it includes the field's parameter in the error message.
 // If the tag is not recognized, it returns the original error message.
 func convertTagToMessage(ex validator.FieldError) string {
 	switch ex.Tag() {
@@ -28,8 +28,12 @@ func convertTagToMessage(ex validator.FieldError) string {
 		return fmt.Sprint("kolom ini harus memiliki panjang minimal ", ex.Param(), " karakter")
 	case "max":
 		return fmt.Sprint("kolom ini harus memiliki panjang maksimal ", ex.Param(), " karakter")
+	case "len":
+		return fmt.Sprint("kolom ini harus memiliki panjang ", ex.Param(), " karakter")
 	case "eqfield":
 		return fmt.Sprint("kolom ini harus sama dengan '", ex.Param(), "'")
+	case "required_without":
+		return fmt.Sprint("kolom ini tidak boleh kosong jika '", ex.Param(), "' kosong")
 	default:
 		return ex.Error()
 	}
@@ -0,0 +1,55 @@
+package exceptions
+
+import (
+	"encoding/json"
+	"go-edash/config"
+	"go-edash/response"
+	"net/http"
+)
+
+type PaymentRequiredError struct {
+	Error string
+}
+
+// NewPaymentRequiredError creates a new PaymentRequiredError with the provided error message.
+//
+// error: the error message to be included in the PaymentRequiredError.
+//
+// Returns a PaymentRequiredError with the provided error message.
+func NewPaymentRequiredError(error string) PaymentRequiredError {
+	return PaymentRequiredError{Error: error}
+}
+
+// PaymentRequiredHandler handles HTTP 402 Payment Required responses.
+// It writes a JSON response with the appropriate status code and error details.
+// If an error occurs while encoding the response, it logs the error.
+//
+// Parameters:
+// - writer: The http.ResponseWriter to write the response to.
+// - err: The error interface containing the details of the error.
+func PaymentRequiredHandler(writer http.ResponseWriter, err any) {
+	// Create a logger for error logging
+	log := config.CreateLoggers(nil)
+
+	// Set the content type of the response to JSON
+	writer.Header().Set("Content-Type", "application/json")
+
+	// Set the status code of the response to Payment Required
+	writer.WriteHeader(http.StatusPaymentRequired)
+
+	// Create an error response with the status code and error details
+	errorResponse := response.ErrorResponse{
+		Code:   http.StatusPaymentRequired,                  // Set the status code to Payment Required
+		Status: http.StatusText(http.StatusPaymentRequired), // Set the status text to the corresponding HTTP status text
+		Errors: err,                                         // Set the error details to the provided error
+	}
+
+	// Encode the error response into JSON
+	encoder := json.NewEncoder(writer)
+
+	// Check if there was an error encoding the response
+	if errEncoder := encoder.Encode(errorResponse); errEncoder != nil {
+		// Log the error if there was an error encoding the response
+		log.Error(errEncoder)
+	}
+}
@@ -0,0 +1,64 @@
+package exceptions
+
+import (
+	"encoding/json"
+	"go-edash/config"
+	"go-edash/response"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type TooManyRequestsError struct {
+	Error      string
+	RetryAfter int64
+}
+
+// NewTooManyRequestsError creates a new TooManyRequestsError with the provided error message.
+//
+// error: the error message to be included in the TooManyRequestsError.
+// retryAfter: how long the client has to wait before trying again, rounded up to the second.
+//
+// Returns a TooManyRequestsError with the provided error message.
+func NewTooManyRequestsError(error string, retryAfter time.Duration) TooManyRequestsError {
+	return TooManyRequestsError{
+		Error:      error,
+		RetryAfter: int64(math.Ceil(retryAfter.Seconds())),
+	}
+}
+
+// TooManyRequestsHandler handles HTTP 429 Too Many Requests responses.
+// It writes a JSON response with the appropriate status code and error details, and sets the Retry-After header.
+// If an error occurs while encoding the response, it logs the error.
+//
+// Parameters:
+// - writer: The http.ResponseWriter to write the response to.
+// - err: The TooManyRequestsError containing the details of the error.
+func TooManyRequestsHandler(writer http.ResponseWriter, err TooManyRequestsError) {
+	// Create a logger for error logging
+	log := config.CreateLoggers(nil)
+
+	// Set the content type of the response to JSON and tell the client when to try again
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Retry-After", strconv.FormatInt(err.RetryAfter, 10))
+
+	// Set the status code of the response to Too Many Requests
+	writer.WriteHeader(http.StatusTooManyRequests)
+
+	// Create an error response with the status code and error details
+	errorResponse := response.ErrorResponse{
+		Code:   http.StatusTooManyRequests,                  // Set the status code to Too Many Requests
+		Status: http.StatusText(http.StatusTooManyRequests), // Set the status text to the corresponding HTTP status text
+		Errors: err,                                         // Set the error details to the provided error
+	}
+
+	// Encode the error response into JSON
+	encoder := json.NewEncoder(writer)
+
+	// Check if there was an error encoding the response
+	if errEncoder := encoder.Encode(errorResponse); errEncoder != nil {
+		// Log the error if there was an error encoding the response
+		log.Error(errEncoder)
+	}
+}
@@ -0,0 +1,55 @@
+package exceptions
+
+import (
+	"encoding/json"
+	"go-edash/config"
+	"go-edash/response"
+	"net/http"
+)
+
+type UnprocessableEntityError struct {
+	Error string
+}
+
+// NewUnprocessableEntityError creates a new UnprocessableEntityError with the provided error message.
+//
+// error: the error message to be included in the UnprocessableEntityError.
+//
+// Returns an UnprocessableEntityError with the provided error message.
+func NewUnprocessableEntityError(error string) UnprocessableEntityError {
+	return UnprocessableEntityError{Error: error}
+}
+
+// UnprocessableEntityHandler is a function that handles HTTP 422 Unprocessable Entity responses.
+// It writes a JSON response with the appropriate status code and error details.
+// If an error occurs while encoding the response, it logs the error.
+//
+// Parameters:
+// - writer: The http.ResponseWriter to write the response to.
+// - err: The error interface containing the details of the error.
+func UnprocessableEntityHandler(writer http.ResponseWriter, err any) {
+	// Create a logger for error logging
+	log := config.CreateLoggers(nil)
+
+	// Set the content type of the response to JSON
+	writer.Header().Set("Content-Type", "application/json")
+
+	// Set the status code of the response to Unprocessable Entity
+	writer.WriteHeader(http.StatusUnprocessableEntity)
+
+	// Create an error response with the status code and error details
+	errorResponse := response.ErrorResponse{
+		Code:   http.StatusUnprocessableEntity,                  // Set the status code to Unprocessable Entity
+		Status: http.StatusText(http.StatusUnprocessableEntity), // Set the status text to the corresponding HTTP status text
+		Errors: err,                                             // Set the error details to the provided error
+	}
+
+	// Encode the error response into JSON
+	encoder := json.NewEncoder(writer)
+
+	// Check if there was an error encoding the response
+	if errEncoder := encoder.Encode(errorResponse); errEncoder != nil {
+		// Log the error if there was an error encoding the response
+		log.Error(errEncoder)
+	}
+}
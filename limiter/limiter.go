@@ -0,0 +1,61 @@
+package limiter
+
+import (
+	"database/sql"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"time"
+)
+
+// window returns how long failed attempts are remembered after the last one.
+// It is read from ATTEMPT_WINDOW and defaults to 1 hour.
+func window() time.Duration {
+	duration := viper.GetDuration("ATTEMPT_WINDOW")
+	if duration <= 0 {
+		return time.Hour
+	}
+
+	return duration
+}
+
+// lockout returns how long a key is locked out after the given number of failed attempts.
+//
+// The key is locked out for ATTEMPT_LOCKOUT (defaults to 30 seconds) when the threshold is reached, and the
+// duration doubles with every further failure, up to ATTEMPT_MAX_LOCKOUT (defaults to 1 hour).
+func lockout(failures int, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+
+	base := viper.GetDuration("ATTEMPT_LOCKOUT")
+	if base <= 0 {
+		base = 30 * time.Second
+	}
+
+	maximum := viper.GetDuration("ATTEMPT_MAX_LOCKOUT")
+	if maximum <= 0 {
+		maximum = time.Hour
+	}
+
+	duration := base
+	for i := threshold; i < failures && duration < maximum; i++ {
+		duration *= 2
+	}
+
+	if duration > maximum {
+		return maximum
+	}
+
+	return duration
+}
+
+// New returns the AttemptLimiter selected by ATTEMPT_LIMITER_STORE.
+// "memory" keeps the counters in the process, which is only suited to a single instance of the application.
+// Any other value, including the default, stores them in MySQL.
+func New(db *sql.DB) domain.AttemptLimiter {
+	if viper.GetString("ATTEMPT_LIMITER_STORE") == "memory" {
+		return NewMemoryLimiter()
+	}
+
+	return NewMysqlLimiter(db)
+}
@@ -0,0 +1,79 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryAttempt struct {
+	failures      int
+	lastFailureAt time.Time
+	lockedUntil   time.Time
+}
+
+// MemoryLimiter is an AttemptLimiter keeping its counters in memory.
+type MemoryLimiter struct {
+	mutex    sync.Mutex
+	attempts map[string]*memoryAttempt
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{attempts: map[string]*memoryAttempt{}}
+}
+
+func (limiter *MemoryLimiter) Check(ctx context.Context, key string) time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	attempt, ok := limiter.attempts[key]
+	if !ok {
+		return 0
+	}
+
+	remaining := time.Until(attempt.lockedUntil)
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+func (limiter *MemoryLimiter) Fail(ctx context.Context, key string, threshold int) time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+
+	limiter.prune(now)
+
+	attempt, ok := limiter.attempts[key]
+	if !ok {
+		attempt = new(memoryAttempt)
+		limiter.attempts[key] = attempt
+	}
+
+	attempt.failures++
+	attempt.lastFailureAt = now
+
+	duration := lockout(attempt.failures, threshold)
+	attempt.lockedUntil = now.Add(duration)
+
+	return duration
+}
+
+func (limiter *MemoryLimiter) Reset(ctx context.Context, key string) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	delete(limiter.attempts, key)
+}
+
+// prune forgets the keys that are not locked out and whose last failure is older than the window.
+func (limiter *MemoryLimiter) prune(now time.Time) {
+	for key, attempt := range limiter.attempts {
+		if now.After(attempt.lockedUntil) && now.Sub(attempt.lastFailureAt) > window() {
+			delete(limiter.attempts, key)
+		}
+	}
+}
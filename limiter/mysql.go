@@ -0,0 +1,126 @@
+package limiter
+
+import (
+	"context"
+	"database/sql"
+	"go-edash/utils"
+	"time"
+)
+
+// MysqlLimiter is an AttemptLimiter keeping its counters in the attempt_counters table,
+// so that they are shared by every instance of the application.
+//
+// Every call runs in its own transaction, so failed attempts are recorded even when the transaction of the
+// request that failed is rolled back.
+type MysqlLimiter struct {
+	db *sql.DB
+}
+
+func NewMysqlLimiter(db *sql.DB) *MysqlLimiter {
+	return &MysqlLimiter{db: db}
+}
+
+func (limiter *MysqlLimiter) Check(ctx context.Context, key string) time.Duration {
+	tx, err := limiter.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	query := "select locked_until from attempt_counters where attempt_key = ?"
+
+	rows, err := tx.QueryContext(ctx, query, key)
+	if err != nil {
+		panic(err)
+	}
+
+	defer rows.Close()
+
+	var lockedUntil sql.NullTime
+	if rows.Next() {
+		err = rows.Scan(&lockedUntil)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if !lockedUntil.Valid {
+		return 0
+	}
+
+	remaining := time.Until(lockedUntil.Time)
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+func (limiter *MysqlLimiter) Fail(ctx context.Context, key string, threshold int) time.Duration {
+	tx, err := limiter.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	query := "select failures, last_failure_at, locked_until from attempt_counters where attempt_key = ? for update"
+
+	rows, err := tx.QueryContext(ctx, query, key)
+	if err != nil {
+		panic(err)
+	}
+
+	failures := 0
+	var lastFailureAt time.Time
+	var lockedUntil sql.NullTime
+
+	if rows.Next() {
+		err = rows.Scan(&failures, &lastFailureAt, &lockedUntil)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	err = rows.Close()
+	if err != nil {
+		panic(err)
+	}
+
+	now := time.Now()
+
+	// Forget the failures older than the window, unless the key is still locked out
+	if (!lockedUntil.Valid || now.After(lockedUntil.Time)) && now.Sub(lastFailureAt) > window() {
+		failures = 0
+	}
+
+	failures++
+
+	duration := lockout(failures, threshold)
+
+	query = `insert into attempt_counters (attempt_key,failures,last_failure_at,locked_until) values (?,?,?,?)
+	on duplicate key update failures=values(failures),last_failure_at=values(last_failure_at),
+	locked_until=values(locked_until)`
+
+	_, err = tx.ExecContext(ctx, query, key, failures, now, now.Add(duration))
+	if err != nil {
+		panic(err)
+	}
+
+	return duration
+}
+
+func (limiter *MysqlLimiter) Reset(ctx context.Context, key string) {
+	tx, err := limiter.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	defer utils.CommitRollback(tx)
+
+	_, err = tx.ExecContext(ctx, "delete from attempt_counters where attempt_key = ?", key)
+	if err != nil {
+		panic(err)
+	}
+}
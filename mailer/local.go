@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/domain"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// unsafeFileName matches the characters that are not kept in the names of the files written by FileMailer.
+var unsafeFileName = regexp.MustCompile(`[^A-Za-z0-9@._-]+`)
+
+// FileMailer writes every email to a .eml file instead of sending it, which can be opened with any mail client.
+// The files are written to MAIL_FILE_DIR, which defaults to storage/mails.
+type FileMailer struct {
+	dir string
+}
+
+func NewFileMailer() *FileMailer {
+	dir := viper.GetString("MAIL_FILE_DIR")
+	if dir == "" {
+		dir = filepath.Join("storage", "mails")
+	}
+
+	return &FileMailer{dir: dir}
+}
+
+func (mailer *FileMailer) Send(ctx context.Context, email *domain.Email) error {
+	err := os.MkdirAll(mailer.dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + unsafeFileName.ReplaceAllString(email.ToEmail, "_") + ".eml"
+
+	return os.WriteFile(filepath.Join(mailer.dir, name), message(sender(), email), 0644)
+}
+
+// ConsoleMailer writes every email to the log instead of sending it.
+type ConsoleMailer struct {
+}
+
+func NewConsoleMailer() *ConsoleMailer {
+	return new(ConsoleMailer)
+}
+
+func (mailer *ConsoleMailer) Send(ctx context.Context, email *domain.Email) error {
+	config.CreateLoggers(nil).Info("Email Not Sent (console mail driver)\n" + string(message(sender(), email)))
+
+	return nil
+}
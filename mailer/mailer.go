@@ -0,0 +1,93 @@
+package mailer
+
+import (
+	"bytes"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/domain"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"time"
+)
+
+// New returns the Mailer selected by MAIL_DRIVER.
+//
+// "smtp" sends through a plain SMTP server (see SmtpMailer). "file" writes every email to a file and "console"
+// writes it to the log, which are meant for development and tests as nothing leaves the machine. Any other value,
+// including the default, sends through Mailjet with MJ_APIKEY_PUBLIC and MJ_APIKEY_PRIVATE.
+func New() domain.Mailer {
+	switch viper.GetString("MAIL_DRIVER") {
+	case "smtp":
+		return NewSmtpMailer()
+	case "file":
+		return NewFileMailer()
+	case "console":
+		return NewConsoleMailer()
+	default:
+		return NewMailjetMailer(config.SetupMailjetClient())
+	}
+}
+
+// sender returns the address the emails are sent from.
+// It is read from MAIL_FROM_EMAIL, falling back to MJ_EMAIL, and MAIL_FROM_NAME, which defaults to "EDash Admin".
+func sender() mail.Address {
+	address := viper.GetString("MAIL_FROM_EMAIL")
+	if address == "" {
+		address = viper.GetString("MJ_EMAIL")
+	}
+
+	name := viper.GetString("MAIL_FROM_NAME")
+	if name == "" {
+		name = "EDash Admin"
+	}
+
+	return mail.Address{Name: name, Address: address}
+}
+
+// message renders the email as an RFC 5322 message, as sent over SMTP or saved in a .eml file.
+// An email with an HTML part is sent as multipart/alternative, with the plain text part first.
+func message(from mail.Address, email *domain.Email) []byte {
+	to := mail.Address{Name: email.ToName, Address: email.ToEmail}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("From: " + from.String() + "\r\n")
+	buffer.WriteString("To: " + to.String() + "\r\n")
+	buffer.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", email.Subject) + "\r\n")
+	buffer.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+
+	if email.Html == "" {
+		buffer.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buffer.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+		buffer.WriteString("\r\n")
+		buffer.Write(crlf(email.Text))
+
+		return buffer.Bytes()
+	}
+
+	parts := multipart.NewWriter(&buffer)
+	buffer.WriteString("Content-Type: multipart/alternative; boundary=" + parts.Boundary() + "\r\n")
+	buffer.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", email.Text},
+		{"text/html", email.Html},
+	} {
+		writer, _ := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		writer.Write(crlf(part.body))
+	}
+
+	parts.Close()
+
+	return buffer.Bytes()
+}
+
+// crlf ends every line of the body with CRLF, as required in a message.
+func crlf(body string) []byte {
+	return append(bytes.ReplaceAll([]byte(body), []byte("\n"), []byte("\r\n")), '\r', '\n')
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+	"github.com/mailjet/mailjet-apiv3-go/v4"
+	"go-edash/domain"
+)
+
+// MailjetMailer sends the emails through the Send API v3.1 of Mailjet.
+type MailjetMailer struct {
+	client *mailjet.Client
+}
+
+func NewMailjetMailer(client *mailjet.Client) *MailjetMailer {
+	return &MailjetMailer{client: client}
+}
+
+func (mailer *MailjetMailer) Send(ctx context.Context, email *domain.Email) error {
+	from := sender()
+
+	messages := &mailjet.MessagesV31{Info: []mailjet.InfoMessagesV31{
+		{
+			From: &mailjet.RecipientV31{
+				Email: from.Address,
+				Name:  from.Name,
+			},
+			To: &mailjet.RecipientsV31{
+				mailjet.RecipientV31{
+					Email: email.ToEmail,
+					Name:  email.ToName,
+				},
+			},
+			Subject:  email.Subject,
+			TextPart: email.Text,
+			HTMLPart: email.Html,
+		},
+	}}
+
+	_, err := mailer.client.SendMailV31(messages)
+
+	return err
+}
@@ -0,0 +1,102 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"go-edash/enums"
+	"strings"
+	"time"
+)
+
+// mailjetEvent is an event posted by the Event API of Mailjet. Only the fields of the bounce, spam and blocked
+// events are read.
+type mailjetEvent struct {
+	Event          string `json:"event"`
+	Time           int64  `json:"time"`
+	Email          string `json:"email"`
+	HardBounce     bool   `json:"hard_bounce"`
+	ErrorRelatedTo string `json:"error_related_to"`
+	Error          string `json:"error"`
+	Source         string `json:"source"`
+}
+
+// ParseMailjetEvents verifies and parses a callback of the Event API of Mailjet, which holds a single event or,
+// when the events are grouped, an array of events.
+//
+// Mailjet does not sign its callbacks, so the callback URL carries basic auth credentials, which must match
+// MAILJET_WEBHOOK_USERNAME and MAILJET_WEBHOOK_PASSWORD. Every callback is rejected when they are not set.
+//
+// Only the events making an address undeliverable are returned: hard bounces, spam reports and emails blocked
+// because of the recipient. Soft bounces and the other events are left out.
+func ParseMailjetEvents(username string, password string, body []byte) ([]domain.EmailEvent, error) {
+	expectedUsername := viper.GetString("MAILJET_WEBHOOK_USERNAME")
+	expectedPassword := viper.GetString("MAILJET_WEBHOOK_PASSWORD")
+
+	if expectedUsername == "" || expectedPassword == "" {
+		return nil, errors.New("mailjet webhook credentials are not configured")
+	}
+
+	validUsername := subtle.ConstantTimeCompare([]byte(username), []byte(expectedUsername))
+	validPassword := subtle.ConstantTimeCompare([]byte(password), []byte(expectedPassword))
+	if validUsername&validPassword != 1 {
+		return nil, errors.New("invalid credentials")
+	}
+
+	var events []mailjetEvent
+
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("[")) {
+		err := json.Unmarshal(body, &events)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		event := mailjetEvent{}
+
+		err := json.Unmarshal(body, &event)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	var results []domain.EmailEvent
+	for _, event := range events {
+		result := domain.EmailEvent{
+			Email: strings.ToLower(event.Email),
+			At:    time.Unix(event.Time, 0),
+		}
+
+		switch event.Event {
+		case "bounce":
+			if !event.HardBounce {
+				continue
+			}
+
+			result.Status = enums.EMAIL_BOUNCED
+			result.Detail = event.ErrorRelatedTo + ": " + event.Error
+		case "spam":
+			result.Status = enums.EMAIL_SPAM
+			result.Detail = event.Source
+		case "blocked":
+			// Emails are also blocked for their content or by the system, which says nothing about the address
+			if event.ErrorRelatedTo != "recipient" {
+				continue
+			}
+
+			result.Status = enums.EMAIL_BLOCKED
+			result.Detail = event.ErrorRelatedTo + ": " + event.Error
+		default:
+			continue
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"go-edash/domain"
+	"go-edash/enums"
+	"time"
+)
+
+// Preview renders the email template in the locale with sample data, to check how the email looks without
+// sending it.
+func Preview(name enums.EmailTemplate, locale enums.Locale) (*domain.Email, error) {
+	expiresAt := time.Now().Add(72 * time.Hour)
+
+	data := map[enums.EmailTemplate]map[string]any{
+		enums.EMAIL_REGISTRATION_OTP: {"Name": "Budi", "Otp": "123456", "Minutes": 5},
+		enums.EMAIL_PASSWORD_RESET: {
+			"Name": "Budi", "Link": "https://edash.example.com/reset-password?token=preview", "Minutes": 30,
+		},
+		enums.EMAIL_PASSWORD_CHANGED: {"Name": "Budi"},
+		enums.EMAIL_EMAIL_CHANGE_OTP: {"Name": "Budi", "Otp": "123456", "Minutes": 5},
+		enums.EMAIL_EMAIL_CHANGED:    {"Name": "Budi", "NewEmail": "budi.baru@example.com"},
+		enums.EMAIL_TRIAL_REMINDER: {
+			"Name": "Budi", "DaysLeft": 7, "LastDay": time.Now().AddDate(0, 0, 6).Format("02-01-2006"),
+		},
+		enums.EMAIL_INVITATION: {
+			"InviterName": "Siti Rahma", "CompanyName": "PT Maju Jaya", "Role": enums.USER,
+			"Link": "https://edash.example.com/invitation?token=preview", "ExpiresAt": expiresAt.Format("02-01-2006 15:04"),
+		},
+		enums.EMAIL_OWNERSHIP_TRANSFER: {
+			"Name": "Budi", "OwnerName": "Siti Rahma", "CompanyName": "PT Maju Jaya", "Otp": "123456", "Minutes": 5,
+		},
+		enums.EMAIL_OWNERSHIP_TRANSFERRED: {"Name": "Siti", "CompanyName": "PT Maju Jaya", "OwnerName": "Budi Santoso"},
+	}
+
+	return Render(name, locale, data[name])
+}
@@ -0,0 +1,134 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SmtpMailer sends the emails through a plain SMTP server.
+//
+// The server is SMTP_HOST on SMTP_PORT (defaults to 587). SMTP_USERNAME and SMTP_PASSWORD authenticate with
+// PLAIN when set, which net/smtp only allows over TLS, upgraded with STARTTLS, or to a local server.
+//
+// Sending an email, from dialing the server to the end of the conversation, is bounded by SMTP_TIMEOUT (defaults
+// to 30 seconds) and by the context, so an unresponsive server cannot hold up the outbox.
+type SmtpMailer struct {
+	host    string
+	addr    string
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+func NewSmtpMailer() *SmtpMailer {
+	host := viper.GetString("SMTP_HOST")
+
+	port := viper.GetString("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	timeout := viper.GetDuration("SMTP_TIMEOUT")
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	mailer := &SmtpMailer{host: host, addr: net.JoinHostPort(host, port), timeout: timeout}
+
+	if username := viper.GetString("SMTP_USERNAME"); username != "" {
+		mailer.auth = smtp.PlainAuth("", username, viper.GetString("SMTP_PASSWORD"), host)
+	}
+
+	return mailer
+}
+
+func (mailer *SmtpMailer) Send(ctx context.Context, email *domain.Email) error {
+	ctx, cancel := context.WithTimeout(ctx, mailer.timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{Timeout: mailer.timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", mailer.addr)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	// Interrupt the conversation as soon as the context is canceled
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	err = mailer.send(conn, email)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// send holds the conversation of smtp.SendMail over the connection.
+func (mailer *SmtpMailer) send(conn net.Conn, email *domain.Email) error {
+	client, err := smtp.NewClient(conn, mailer.host)
+	if err != nil {
+		return err
+	}
+
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: mailer.host})
+		if err != nil {
+			return err
+		}
+	}
+
+	if mailer.auth != nil {
+		err = client.Auth(mailer.auth)
+		if err != nil {
+			return err
+		}
+	}
+
+	from := sender()
+
+	err = client.Mail(from.Address)
+	if err != nil {
+		return err
+	}
+
+	err = client.Rcpt(email.ToEmail)
+	if err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(message(from, email))
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
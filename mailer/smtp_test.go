@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"context"
+	"go-edash/domain"
+	"net"
+	"testing"
+	"time"
+)
+
+// silentServer accepts connections and never answers, like a hung SMTP server.
+func silentServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, errAccept := listener.Accept()
+			if errAccept != nil {
+				return
+			}
+
+			t.Cleanup(func() {
+				_ = conn.Close()
+			})
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestSmtpMailerSendTimesOut(t *testing.T) {
+	mailer := &SmtpMailer{host: "127.0.0.1", addr: silentServer(t), timeout: 200 * time.Millisecond}
+
+	start := time.Now()
+
+	err := mailer.Send(context.Background(), &domain.Email{ToEmail: "budi@example.com"})
+	if err == nil {
+		t.Fatal("expected the send to time out")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the send to give up after its timeout, took %s", elapsed)
+	}
+}
+
+func TestSmtpMailerSendStopsWhenCanceled(t *testing.T) {
+	mailer := &SmtpMailer{host: "127.0.0.1", addr: silentServer(t), timeout: time.Minute}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	err := mailer.Send(ctx, &domain.Email{ToEmail: "budi@example.com"})
+	if err == nil {
+		t.Fatal("expected the send to be canceled")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the send to stop with its context, took %s", elapsed)
+	}
+}
@@ -0,0 +1,117 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"go-edash/domain"
+	"go-edash/enums"
+	htmltemplate "html/template"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// templateFiles holds the email templates, in a directory per locale.
+//
+// Every locale has a layout.html wrapping the HTML part of its emails and a <template>.tmpl per email, which
+// defines the "subject", the plain text "text" and the HTML "content" of the email.
+//
+//go:embed templates
+var templateFiles embed.FS
+
+// emailTemplate is an email template parsed for both of the parts of the email.
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// templates holds the parsed templates by locale and name. A template that does not parse stops the application
+// when it starts, instead of failing when the email is sent.
+var templates = parseTemplates()
+
+func parseTemplates() map[enums.Locale]map[enums.EmailTemplate]*emailTemplate {
+	parsed := make(map[enums.Locale]map[enums.EmailTemplate]*emailTemplate)
+
+	locales, err := templateFiles.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, locale := range locales {
+		dir := path.Join("templates", locale.Name())
+
+		layout := htmltemplate.Must(htmltemplate.New("layout").Option("missingkey=error").
+			ParseFS(templateFiles, path.Join(dir, "layout.html")))
+
+		files, errDir := templateFiles.ReadDir(dir)
+		if errDir != nil {
+			panic(errDir)
+		}
+
+		parsed[enums.Locale(locale.Name())] = make(map[enums.EmailTemplate]*emailTemplate)
+
+		for _, file := range files {
+			name, found := strings.CutSuffix(file.Name(), ".tmpl")
+			if !found {
+				continue
+			}
+
+			filename := path.Join(dir, file.Name())
+
+			parsed[enums.Locale(locale.Name())][enums.EmailTemplate(name)] = &emailTemplate{
+				text: texttemplate.Must(texttemplate.New(name).Option("missingkey=error").ParseFS(templateFiles, filename)),
+				html: htmltemplate.Must(htmltemplate.Must(layout.Clone()).ParseFS(templateFiles, filename)),
+			}
+		}
+	}
+
+	return parsed
+}
+
+// Render renders the email template in the locale, falling back to the default locale when the template does not
+// exist in the locale. The data is available to the template along with the Locale and, in the HTML part, the
+// Subject of the email.
+//
+// The email is returned without any recipient.
+func Render(name enums.EmailTemplate, locale enums.Locale, data map[string]any) (*domain.Email, error) {
+	tmpl, found := templates[locale][name]
+	if !found {
+		locale = enums.DEFAULT_LOCALE
+		tmpl, found = templates[locale][name]
+	}
+
+	if !found {
+		return nil, errors.New("email template " + string(name) + " not found")
+	}
+
+	values := map[string]any{"Locale": locale}
+	for key, value := range data {
+		values[key] = value
+	}
+
+	var subject, text, html bytes.Buffer
+
+	err := tmpl.text.ExecuteTemplate(&subject, "subject", values)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tmpl.text.ExecuteTemplate(&text, "text", values)
+	if err != nil {
+		return nil, err
+	}
+
+	values["Subject"] = strings.TrimSpace(subject.String())
+
+	err = tmpl.html.ExecuteTemplate(&html, "layout", values)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Email{
+		Subject: values["Subject"].(string),
+		Text:    strings.TrimSpace(text.String()),
+		Html:    html.String(),
+	}, nil
+}
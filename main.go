@@ -1,16 +1,28 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/spf13/viper"
 	"go-edash/app/company"
+	"go-edash/app/outbox"
+	"go-edash/app/payment"
+	"go-edash/app/subscription"
+	"go-edash/app/trial"
 	"go-edash/app/user"
 	"go-edash/app/welcome"
 	"go-edash/config"
+	"go-edash/limiter"
+	"go-edash/mailer"
+	"go-edash/messaging"
 	"go-edash/middlewares"
+	paymentProvider "go-edash/payment"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -19,27 +31,93 @@ import (
 func main() {
 	config.InitConfig()
 	log := config.CreateLoggers(nil)
-	mailjetClient := config.SetupMailjetClient()
+	mail := mailer.New()
 	validate := config.CreateValidator()
 	db, err := config.ConnectDatabase()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	err = config.RequireSecrets("OTP_SECRET", "INVITATION_SECRET")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.SetupTokenRevocation(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.LoadSigningKeys()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Reload the signing keys on SIGHUP, which is how they are rotated
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	go func() {
+		for range reload {
+			if errReload := config.LoadSigningKeys(); errReload != nil {
+				log.Error(errReload)
+				continue
+			}
+
+			log.Info("Signing Keys Reloaded")
+		}
+	}()
+
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
+	router.Use(middlewares.ClientIPMiddleware)
 	router.Use(middlewares.LoggerMiddleware)
 	router.Use(middlewares.RecoverMiddleware)
 	router.Use(middleware.Timeout(60 * time.Second))
 
 	welcomeHandler := welcome.Wire()
 
-	user.Wire(validate, db, mailjetClient).InitializeRoute(router)
-	company.Wire(validate, db).InitializeRoute(router)
+	attemptLimiter := limiter.New(db)
+	messenger := messaging.New()
+
+	provider, err := paymentProvider.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	user.Wire(validate, db, mail, messenger, attemptLimiter).InitializeRoute(router)
+	company.Wire(validate, db, mail, messenger).InitializeRoute(router)
+	subscription.Wire(db).InitializeRoute(router)
+	payment.Wire(validate, db, provider).InitializeRoute(router)
+	outbox.Wire(validate, db, mail, messenger).InitializeRoute(router)
+
+	outboxService := outbox.WireService(db, mail, messenger)
+	config.ScheduleEvery("outbox", viper.GetDuration("OUTBOX_POLL_INTERVAL"), 5*time.Second, func() {
+		outboxService.Dispatch(context.Background())
+	})
+
+	config.ScheduleDaily("outbox-purge", viper.GetString("OUTBOX_PURGE_JOB_TIME"), "03:00", func() {
+		outboxService.PurgeMessages(context.Background())
+	})
+
+	trialService := trial.Wire(db, mail, messenger)
+	config.ScheduleDaily("trial", viper.GetString("TRIAL_JOB_TIME"), "01:00", func() {
+		trialService.RunDailyJob(context.Background())
+	})
+
+	companyLifecycleService := company.WireLifecycle(db, mail, messenger)
+	config.ScheduleDaily("company-purge", viper.GetString("COMPANY_PURGE_JOB_TIME"), "02:00", func() {
+		companyLifecycleService.PurgeDeletedCompanies(context.Background())
+	})
 
 	router.Get("/", welcomeHandler.Welcome())
+	router.Get("/.well-known/jwks.json", welcomeHandler.Jwks())
+
+	if config.IsDevelopment() {
+		router.Get("/dev/emails/{template}", welcomeHandler.PreviewEmail())
+	}
+
 	router.NotFound(welcomeHandler.NotFoundApi())
 	router.MethodNotAllowed(welcomeHandler.MethodNotAllowedApi())
 
@@ -0,0 +1,24 @@
+package messaging
+
+import (
+	"context"
+	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/enums"
+)
+
+// FakeChannel writes every message to the log instead of sending it.
+type FakeChannel struct {
+	channel enums.MessageChannel
+}
+
+func NewFakeChannel(channel enums.MessageChannel) *FakeChannel {
+	return &FakeChannel{channel: channel}
+}
+
+func (channel *FakeChannel) Send(ctx context.Context, message *domain.Message) error {
+	config.CreateLoggers(nil).Info("Message Not Sent (fake " + string(channel.channel) + " channel) to " +
+		message.ToPhone + ": " + message.Text)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"go-edash/enums"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Messenger sends every message through the MessageChannel configured for its channel.
+type Messenger struct {
+	channels map[enums.MessageChannel]domain.MessageChannel
+}
+
+// New returns the Messenger whose channels are selected by MESSAGING_DRIVER.
+//
+// "fake" writes every message to the log (see FakeChannel), which is meant for development and tests as nothing
+// leaves the machine. Any other value, including the default, sends SMS through SmsChannel and WhatsApp messages
+// through WhatsAppChannel.
+func New() *Messenger {
+	if viper.GetString("MESSAGING_DRIVER") == "fake" {
+		return NewMessenger(map[enums.MessageChannel]domain.MessageChannel{
+			enums.CHANNEL_SMS:      NewFakeChannel(enums.CHANNEL_SMS),
+			enums.CHANNEL_WHATSAPP: NewFakeChannel(enums.CHANNEL_WHATSAPP),
+		})
+	}
+
+	return NewMessenger(map[enums.MessageChannel]domain.MessageChannel{
+		enums.CHANNEL_SMS:      NewSmsChannel(),
+		enums.CHANNEL_WHATSAPP: NewWhatsAppChannel(),
+	})
+}
+
+func NewMessenger(channels map[enums.MessageChannel]domain.MessageChannel) *Messenger {
+	return &Messenger{channels: channels}
+}
+
+func (messenger *Messenger) Send(ctx context.Context, channel enums.MessageChannel, message *domain.Message) error {
+	messageChannel, found := messenger.channels[channel]
+	if !found {
+		return errors.New("message channel " + string(channel) + " is not configured")
+	}
+
+	return messageChannel.Send(ctx, message)
+}
+
+// checkResponse returns an error holding the start of the body when the provider did not accept the message.
+func checkResponse(provider string, response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1<<10))
+
+	return errors.New(provider + " rejected the message with status " + strconv.Itoa(response.StatusCode) + ": " +
+		strings.TrimSpace(string(body)))
+}
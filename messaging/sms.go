@@ -0,0 +1,65 @@
+package messaging
+
+import (
+	"context"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SmsChannel sends the messages as SMS through the Messages API of Twilio, or any gateway compatible with it.
+//
+// It is configured by SMS_ACCOUNT_SID, SMS_AUTH_TOKEN and SMS_FROM, the sender number or alphanumeric sender id.
+// SMS_API_URL defaults to the API of Twilio.
+type SmsChannel struct {
+	baseUrl    string
+	accountSid string
+	authToken  string
+	from       string
+	client     *http.Client
+}
+
+func NewSmsChannel() *SmsChannel {
+	baseUrl := viper.GetString("SMS_API_URL")
+	if baseUrl == "" {
+		baseUrl = "https://api.twilio.com"
+	}
+
+	return &SmsChannel{
+		baseUrl:    baseUrl,
+		accountSid: viper.GetString("SMS_ACCOUNT_SID"),
+		authToken:  viper.GetString("SMS_AUTH_TOKEN"),
+		from:       viper.GetString("SMS_FROM"),
+		client:     &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (channel *SmsChannel) Send(ctx context.Context, message *domain.Message) error {
+	form := url.Values{
+		"To":   {message.ToPhone},
+		"From": {channel.from},
+		"Body": {message.Text},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		channel.baseUrl+"/2010-04-01/Accounts/"+url.PathEscape(channel.accountSid)+"/Messages.json",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	request.SetBasicAuth(channel.accountSid, channel.authToken)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := channel.client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	return checkResponse("sms gateway", response)
+}
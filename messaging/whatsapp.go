@@ -0,0 +1,119 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WhatsAppChannel sends the messages through the Cloud API of WhatsApp Business.
+//
+// WhatsApp only lets a business start a conversation with a pre-approved template, so the OTP of the message is
+// sent through the authentication template named by WHATSAPP_OTP_TEMPLATE (defaults to "otp"), in the language of
+// the locale of the message. The template must exist in every locale of the application.
+//
+// It is configured by WHATSAPP_PHONE_NUMBER_ID and WHATSAPP_ACCESS_TOKEN. WHATSAPP_API_URL defaults to the
+// Graph API of Meta.
+type WhatsAppChannel struct {
+	baseUrl       string
+	phoneNumberId string
+	accessToken   string
+	template      string
+	client        *http.Client
+}
+
+type (
+	whatsAppMessage struct {
+		MessagingProduct string           `json:"messaging_product"`
+		To               string           `json:"to"`
+		Type             string           `json:"type"`
+		Template         whatsAppTemplate `json:"template"`
+	}
+
+	whatsAppTemplate struct {
+		Name     string `json:"name"`
+		Language struct {
+			Code string `json:"code"`
+		} `json:"language"`
+		Components []whatsAppComponent `json:"components"`
+	}
+
+	whatsAppComponent struct {
+		Type       string              `json:"type"`
+		SubType    string              `json:"sub_type,omitempty"`
+		Index      string              `json:"index,omitempty"`
+		Parameters []whatsAppParameter `json:"parameters"`
+	}
+
+	whatsAppParameter struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+)
+
+func NewWhatsAppChannel() *WhatsAppChannel {
+	baseUrl := viper.GetString("WHATSAPP_API_URL")
+	if baseUrl == "" {
+		baseUrl = "https://graph.facebook.com/v19.0"
+	}
+
+	template := viper.GetString("WHATSAPP_OTP_TEMPLATE")
+	if template == "" {
+		template = "otp"
+	}
+
+	return &WhatsAppChannel{
+		baseUrl:       baseUrl,
+		phoneNumberId: viper.GetString("WHATSAPP_PHONE_NUMBER_ID"),
+		accessToken:   viper.GetString("WHATSAPP_ACCESS_TOKEN"),
+		template:      template,
+		client:        &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (channel *WhatsAppChannel) Send(ctx context.Context, message *domain.Message) error {
+	// Authentication templates show the code in their body and copy it with their button
+	code := []whatsAppParameter{{Type: "text", Text: message.Otp}}
+
+	payload := whatsAppMessage{
+		MessagingProduct: "whatsapp",
+		To:               strings.TrimPrefix(message.ToPhone, "+"),
+		Type:             "template",
+		Template: whatsAppTemplate{
+			Name: channel.template,
+			Components: []whatsAppComponent{
+				{Type: "body", Parameters: code},
+				{Type: "button", SubType: "url", Index: "0", Parameters: code},
+			},
+		},
+	}
+	payload.Template.Language.Code = string(message.Locale)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.baseUrl+"/"+channel.phoneNumberId+"/messages",
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+channel.accessToken)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := channel.client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	return checkResponse("whatsapp", response)
+}
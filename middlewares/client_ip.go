@@ -0,0 +1,114 @@
+package middlewares
+
+import (
+	"github.com/spf13/viper"
+	"go-edash/domain"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// trustedProxies returns the networks of the proxies allowed to tell the address of the client, read once from
+// TRUSTED_PROXIES as a comma separated list of IP addresses and CIDR ranges. Invalid entries are ignored.
+var trustedProxies = sync.OnceValue(func() []*net.IPNet {
+	return parseTrustedProxies(viper.GetString("TRUSTED_PROXIES"))
+})
+
+func parseTrustedProxies(value string) []*net.IPNet {
+	var networks []*net.IPNet
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			continue
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+func isTrusted(ip string, trusted []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientIP returns the IP address of the client of the request.
+//
+// The X-Forwarded-For and X-Real-IP headers are only honoured when the request comes from a trusted proxy, as
+// anybody else could set them. X-Forwarded-For is read from the right, skipping the trusted proxies, so that the
+// address returned is the last one added by a proxy that can be trusted rather than one made up by the client.
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	if !isTrusted(ip, trusted) {
+		return ip
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+
+			ip = hop
+			if !isTrusted(hop, trusted) {
+				break
+			}
+		}
+
+		return ip
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	return ip
+}
+
+// ClientIPMiddleware is a middleware function that places the IP address of the client in the request context,
+// where services read it with domain.ClientIPFromContext, and in the RemoteAddr of the request.
+// Behind a proxy listed in TRUSTED_PROXIES, the address is taken from the headers set by the proxy.
+func ClientIPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r, trustedProxies())
+
+		r.RemoteAddr = ip
+
+		ctx := domain.NewClientIPContext(r.Context(), ip)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := parseTrustedProxies("10.0.0.0/8, 192.168.1.10, invalid")
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		realIP       string
+		expectedIP   string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.7:5000", expectedIP: "203.0.113.7"},
+		{name: "untrusted peer cannot spoof X-Forwarded-For", remoteAddr: "203.0.113.7:5000", forwardedFor: "1.2.3.4", expectedIP: "203.0.113.7"},
+		{name: "untrusted peer cannot spoof X-Real-IP", remoteAddr: "203.0.113.7:5000", realIP: "1.2.3.4", expectedIP: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "10.1.2.3:5000", forwardedFor: "198.51.100.4", expectedIP: "198.51.100.4"},
+		{name: "trusted single address", remoteAddr: "192.168.1.10:5000", realIP: "198.51.100.4", expectedIP: "198.51.100.4"},
+		{name: "address prepended by the client is skipped", remoteAddr: "10.1.2.3:5000", forwardedFor: "1.2.3.4, 198.51.100.4", expectedIP: "198.51.100.4"},
+		{name: "chain of trusted proxies", remoteAddr: "10.1.2.3:5000", forwardedFor: "198.51.100.4, 10.9.9.9", expectedIP: "198.51.100.4"},
+		{name: "malformed hop", remoteAddr: "10.1.2.3:5000", forwardedFor: "not-an-ip", expectedIP: "10.1.2.3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/", nil)
+			request.RemoteAddr = test.remoteAddr
+
+			if test.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", test.forwardedFor)
+			}
+
+			if test.realIP != "" {
+				request.Header.Set("X-Real-IP", test.realIP)
+			}
+
+			if ip := clientIP(request, trusted); ip != test.expectedIP {
+				t.Fatalf("expected %s, got %s", test.expectedIP, ip)
+			}
+		})
+	}
+}
@@ -54,6 +54,36 @@ func RecoverMiddleware(next http.Handler) http.Handler {
 					return
 				}
 
+				// Check if the error is an UnprocessableEntityError
+				if str, ok := err.(exceptions.UnprocessableEntityError); ok {
+					exceptions.UnprocessableEntityHandler(writer, str)
+					return
+				}
+
+				// Check if the error is an UnauthorizedError
+				if str, ok := err.(exceptions.UnauthorizedError); ok {
+					exceptions.UnauthorizedHandler(writer, str)
+					return
+				}
+
+				// Check if the error is a ForbiddenError
+				if str, ok := err.(exceptions.ForbiddenError); ok {
+					exceptions.ForbiddenHandler(writer, str)
+					return
+				}
+
+				// Check if the error is a PaymentRequiredError
+				if str, ok := err.(exceptions.PaymentRequiredError); ok {
+					exceptions.PaymentRequiredHandler(writer, str)
+					return
+				}
+
+				// Check if the error is a TooManyRequestsError
+				if str, ok := err.(exceptions.TooManyRequestsError); ok {
+					exceptions.TooManyRequestsHandler(writer, str)
+					return
+				}
+
 				// Check if the error is a string
 				if str, ok := err.(string); ok {
 					// Call InternalServerHandler to send error response
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"net/http"
+)
+
+// RequireOnboardingStep returns a middleware that only lets through the users whose onboarding reached the step,
+// as carried by their access token.
+// It must run after VerifyTokenMiddleware. Requests made by users at an earlier step get a 403 Forbidden response.
+func RequireOnboardingStep(step enums.RegistrationStep) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := domain.PrincipalFromContext(r.Context())
+			if !ok {
+				exceptions.UnauthorizedHandler(w, exceptions.NewUnauthorizedError("unauthenticated request"))
+				return
+			}
+
+			if !principal.RegistrationStep.Reached(step) {
+				exceptions.ForbiddenHandler(w, exceptions.NewForbiddenError("onboarding step "+step.String()+" required"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
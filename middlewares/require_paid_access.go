@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"go-edash/domain"
+	"go-edash/exceptions"
+	"net/http"
+	"time"
+)
+
+// RequirePaidAccess returns a middleware guarding the paid features, which are available to the companies
+// with a subscription and during the free trial.
+//
+// It must run after VerifyTokenMiddleware. A trial still running according to the access token lets the request
+// through right away, otherwise the entitlements of the company of the user are checked.
+// Requests made without entitlements get a 402 Payment Required response.
+func RequirePaidAccess(entitlements domain.EntitlementService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := domain.PrincipalFromContext(r.Context())
+			if !ok {
+				exceptions.UnauthorizedHandler(w, exceptions.NewUnauthorizedError("unauthenticated request"))
+				return
+			}
+
+			if time.Now().Before(principal.TrialEndsAt) || entitlements.HasPaidAccess(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			exceptions.PaymentRequiredHandler(w, exceptions.NewPaymentRequiredError("no active subscription"))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"go-edash/domain"
+	"go-edash/enums"
+	"go-edash/exceptions"
+	"net/http"
+)
+
+// roleFromRequest returns the role of the authenticated principal.
+// It must run after VerifyTokenMiddleware, otherwise no role is found.
+func roleFromRequest(r *http.Request) (enums.Role, bool) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		return "", false
+	}
+
+	return principal.Role, true
+}
+
+// RequirePermission returns a middleware that only lets through the roles granted the permission
+// by the permission matrix of the enums package.
+// Requests made with any other role get a 403 Forbidden response.
+func RequirePermission(permission enums.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := roleFromRequest(r)
+			if !ok {
+				exceptions.UnauthorizedHandler(w, exceptions.NewUnauthorizedError("missing role"))
+				return
+			}
+
+			if !role.HasPermission(permission) {
+				exceptions.ForbiddenHandler(w, exceptions.NewForbiddenError("missing permission "+string(permission)))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,15 +1,73 @@
 package middlewares
 
 import (
-	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
 	"go-edash/config"
+	"go-edash/domain"
+	"go-edash/enums"
 	"net/http"
 	"strings"
+	"time"
 )
 
+// principalFromClaims builds the authenticated principal from the claims of a verified access token.
+// It returns an error instead of panicking when a claim is missing or has an unexpected type.
+func principalFromClaims(claims jwt.MapClaims) (*domain.Principal, time.Time, error) {
+	principal := new(domain.Principal)
+
+	principal.UserId, _ = claims.GetSubject()
+	principal.Email, _ = claims["eml"].(string)
+	principal.CompanyId, _ = claims["cid"].(string)
+	principal.SessionId, _ = claims["sid"].(string)
+	principal.TokenId, _ = claims["jti"].(string)
+
+	step, ok := claims["stp"].(float64)
+	if !ok {
+		return nil, time.Time{}, errors.New("invalid token")
+	}
+
+	principal.RegistrationStep = enums.RegistrationStep(step)
+
+	trialEnd, ok := claims["tre"].(float64)
+	if !ok {
+		return nil, time.Time{}, errors.New("invalid token")
+	}
+
+	if trialEnd > 0 {
+		principal.TrialEndsAt = time.Unix(int64(trialEnd), 0)
+	}
+
+	audience, err := claims.GetAudience()
+	if err != nil || len(audience) == 0 {
+		return nil, time.Time{}, errors.New("invalid token")
+	}
+
+	principal.Role = enums.Role(audience[0])
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, time.Time{}, errors.New("invalid token")
+	}
+
+	principal.ExpiresAt = exp.Time
+
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil {
+		return nil, time.Time{}, errors.New("invalid token")
+	}
+
+	if principal.UserId == "" || principal.Email == "" || principal.TokenId == "" {
+		return nil, time.Time{}, errors.New("invalid token")
+	}
+
+	return principal, iat.Time, nil
+}
+
 // VerifyTokenMiddleware is a middleware function that verifies the JWT token in the request header.
-// If the token is valid, it allows the request to proceed to the next handler.
-// If the token is invalid or missing, it returns a 401 Unauthorized response.
+// If the token is valid, it places the domain.Principal described by the token in the request context, scopes the
+// context to the active company of the principal and allows the request to proceed to the next handler.
+// If the token is invalid, missing or has been revoked, it returns a 401 Unauthorized response.
 func VerifyTokenMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Extract the token from the request header
@@ -26,8 +84,24 @@ func VerifyTokenMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		claims := verify.Claims
-		ctx := context.WithValue(r.Context(), "claims", claims)
+		principal, issuedAt, err := principalFromClaims(verify.Claims.(jwt.MapClaims))
+		if err != nil {
+			http.Error(w, err.Error(), 401)
+			return
+		}
+
+		// Reject the token if it has been revoked by a logout
+		if config.IsTokenRevoked(principal.TokenId, principal.UserId, issuedAt) {
+			http.Error(w, "token revoked", 401)
+			return
+		}
+
+		ctx := domain.NewPrincipalContext(r.Context(), principal)
+
+		// Scope the request to the active company, which the repositories constrain their queries by
+		if principal.CompanyId != "" {
+			ctx = domain.NewTenantContext(ctx, principal.CompanyId)
+		}
 
 		// If the token is valid, allow the request to proceed to the next handler
 		next.ServeHTTP(w, r.WithContext(ctx))
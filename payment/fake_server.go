@@ -0,0 +1,163 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeServer is a local stand-in for the Snap API of Midtrans, used in development and tests.
+//
+// It accepts the transactions created by MidtransProvider and answers with a redirect URL on itself. Opening
+// that URL settles the transaction, or applies the status given by the "status" query parameter, and sends
+// a signed notification to the webhook of the application, exactly like Midtrans would.
+type FakeServer struct {
+	listener   net.Listener
+	serverKey  string
+	webhookUrl string
+	client     *http.Client
+
+	mutex   sync.Mutex
+	amounts map[string]int64
+}
+
+// StartFakeServer starts a FakeServer listening on PAYMENT_FAKE_ADDR (defaults to a random local port).
+// Its notifications are sent to PAYMENT_FAKE_WEBHOOK_URL, which defaults to the payment webhook of the
+// application on APP_PORT.
+func StartFakeServer() *FakeServer {
+	addr := viper.GetString("PAYMENT_FAKE_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+
+	webhookUrl := viper.GetString("PAYMENT_FAKE_WEBHOOK_URL")
+	if webhookUrl == "" {
+		webhookUrl = "http://127.0.0.1:" + viper.GetString("APP_PORT") + "/api/payment/webhook"
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		panic(err)
+	}
+
+	server := &FakeServer{
+		listener:   listener,
+		serverKey:  "fake-server-key",
+		webhookUrl: webhookUrl,
+		client:     &http.Client{Timeout: 15 * time.Second},
+		amounts:    map[string]int64{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/snap/v1/transactions", server.createTransaction)
+	mux.HandleFunc("/pay/", server.pay)
+
+	go func() {
+		_ = http.Serve(listener, mux)
+	}()
+
+	config.CreateLoggers(nil).Info("Fake Payment Server Listening On " + server.URL())
+
+	return server
+}
+
+// URL returns the base URL of the server.
+func (server *FakeServer) URL() string {
+	return "http://" + server.listener.Addr().String()
+}
+
+// ServerKey returns the server key the notifications are signed with.
+func (server *FakeServer) ServerKey() string {
+	return server.serverKey
+}
+
+func (server *FakeServer) createTransaction(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+
+	username, _, ok := request.BasicAuth()
+	if !ok || username != server.serverKey {
+		writer.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(writer).Encode(midtransTransactionResponse{ErrorMessages: []string{"unauthorized"}})
+		return
+	}
+
+	payload := new(midtransTransactionRequest)
+
+	err := json.NewDecoder(request.Body).Decode(payload)
+	if err != nil || payload.TransactionDetails.OrderId == "" {
+		writer.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(writer).Encode(midtransTransactionResponse{ErrorMessages: []string{"invalid transaction"}})
+		return
+	}
+
+	server.mutex.Lock()
+	server.amounts[payload.TransactionDetails.OrderId] = payload.TransactionDetails.GrossAmount
+	server.mutex.Unlock()
+
+	orderId := payload.TransactionDetails.OrderId
+
+	writer.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(writer).Encode(midtransTransactionResponse{
+		Token:       "fake-" + orderId,
+		RedirectUrl: server.URL() + "/pay/" + orderId,
+	})
+}
+
+func (server *FakeServer) pay(writer http.ResponseWriter, request *http.Request) {
+	orderId := strings.TrimPrefix(request.URL.Path, "/pay/")
+
+	server.mutex.Lock()
+	amount, ok := server.amounts[orderId]
+	server.mutex.Unlock()
+
+	if !ok {
+		http.NotFound(writer, request)
+		return
+	}
+
+	status := request.URL.Query().Get("status")
+	if status == "" {
+		status = "settlement"
+	}
+
+	statusCode := "200"
+	if status != "settlement" && status != "capture" {
+		statusCode = "202"
+	}
+
+	grossAmount := strconv.FormatInt(amount, 10) + ".00"
+
+	notification := midtransNotification{
+		OrderId:           orderId,
+		TransactionId:     orderId + "-" + status,
+		TransactionStatus: status,
+		FraudStatus:       "accept",
+		StatusCode:        statusCode,
+		GrossAmount:       grossAmount,
+		SignatureKey:      midtransSignature(orderId, statusCode, grossAmount, server.serverKey),
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := server.client.Post(server.webhookUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	_ = response.Body.Close()
+
+	writer.WriteHeader(response.StatusCode)
+	_, _ = writer.Write([]byte("notification sent: " + status + "\n"))
+}
@@ -0,0 +1,176 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-edash/domain"
+	"go-edash/enums"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MidtransProvider is a PaymentProvider backed by the Snap API of Midtrans.
+type MidtransProvider struct {
+	baseUrl   string
+	serverKey string
+	client    *http.Client
+}
+
+func NewMidtransProvider(baseUrl string, serverKey string) *MidtransProvider {
+	return &MidtransProvider{
+		baseUrl:   baseUrl,
+		serverKey: serverKey,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type (
+	midtransTransactionRequest struct {
+		TransactionDetails struct {
+			OrderId     string `json:"order_id"`
+			GrossAmount int64  `json:"gross_amount"`
+		} `json:"transaction_details"`
+		ItemDetails []midtransItem `json:"item_details"`
+		Customer    struct {
+			FirstName string `json:"first_name"`
+			Email     string `json:"email"`
+		} `json:"customer_details"`
+	}
+
+	midtransItem struct {
+		Id       string `json:"id"`
+		Price    int64  `json:"price"`
+		Quantity int    `json:"quantity"`
+		Name     string `json:"name"`
+	}
+
+	midtransTransactionResponse struct {
+		Token         string   `json:"token"`
+		RedirectUrl   string   `json:"redirect_url"`
+		ErrorMessages []string `json:"error_messages"`
+	}
+
+	midtransNotification struct {
+		OrderId           string `json:"order_id"`
+		TransactionId     string `json:"transaction_id"`
+		TransactionStatus string `json:"transaction_status"`
+		FraudStatus       string `json:"fraud_status"`
+		StatusCode        string `json:"status_code"`
+		GrossAmount       string `json:"gross_amount"`
+		SignatureKey      string `json:"signature_key"`
+	}
+)
+
+// midtransSignature computes the signature Midtrans puts in its notifications:
+// the hex SHA-512 of the order id, the status code, the gross amount and the server key.
+func midtransSignature(orderId string, statusCode string, grossAmount string, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderId + statusCode + grossAmount + serverKey))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (provider *MidtransProvider) CreateCharge(ctx context.Context, charge *domain.PaymentCharge) (*domain.PaymentCheckout, error) {
+	payload := new(midtransTransactionRequest)
+	payload.TransactionDetails.OrderId = charge.OrderId
+	payload.TransactionDetails.GrossAmount = charge.Amount
+	payload.ItemDetails = []midtransItem{
+		{Id: charge.OrderId, Price: charge.Amount, Quantity: 1, Name: charge.ItemName},
+	}
+	payload.Customer.FirstName = charge.CustomerName
+	payload.Customer.Email = charge.CustomerEmail
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.baseUrl+"/snap/v1/transactions",
+		bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	request.SetBasicAuth(provider.serverKey, "")
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := provider.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	result := new(midtransTransactionResponse)
+
+	err = json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Midtrans answers a transaction it refuses with a client error, while the transaction may have been created
+	// when it answers with a server error
+	if response.StatusCode >= http.StatusBadRequest && response.StatusCode < http.StatusInternalServerError {
+		return nil, domain.PaymentRejectedError{Message: fmt.Sprintf(
+			"midtrans rejected the transaction with status %d: %v", response.StatusCode, result.ErrorMessages)}
+	}
+
+	if response.StatusCode != http.StatusCreated || result.Token == "" {
+		return nil, fmt.Errorf("midtrans failed to create the transaction with status %d: %v", response.StatusCode,
+			result.ErrorMessages)
+	}
+
+	return &domain.PaymentCheckout{Reference: result.Token, RedirectUrl: result.RedirectUrl}, nil
+}
+
+func (provider *MidtransProvider) ParseNotification(body []byte) (*domain.PaymentNotification, error) {
+	notification := new(midtransNotification)
+
+	err := json.Unmarshal(body, notification)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := midtransSignature(notification.OrderId, notification.StatusCode, notification.GrossAmount,
+		provider.serverKey)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(notification.SignatureKey)) != 1 {
+		return nil, errors.New("invalid signature")
+	}
+
+	amount, err := strconv.ParseFloat(notification.GrossAmount, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.PaymentNotification{
+		OrderId:       notification.OrderId,
+		TransactionId: notification.TransactionId,
+		Amount:        int64(amount),
+	}
+
+	switch notification.TransactionStatus {
+	case "settlement":
+		result.Status = enums.PAYMENT_PAID
+	case "capture":
+		// Card payments are captured first and only count as paid once the fraud check accepted them
+		if notification.FraudStatus == "accept" {
+			result.Status = enums.PAYMENT_PAID
+		} else {
+			result.Status = enums.PAYMENT_PENDING
+		}
+	case "deny", "cancel", "expire", "failure":
+		result.Status = enums.PAYMENT_FAILED
+	default:
+		result.Status = enums.PAYMENT_PENDING
+	}
+
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package payment
+
+import (
+	"errors"
+	"github.com/spf13/viper"
+	"go-edash/config"
+	"go-edash/domain"
+)
+
+// New returns the PaymentProvider selected by PAYMENT_PROVIDER.
+//
+// "fake" starts the local fake server (see FakeServer) and returns a Midtrans provider talking to it, which is
+// meant for development and tests. As the fake server signs its notifications with a key anybody can read, it
+// is refused outside development. Any other value, including the default, returns the Midtrans provider
+// configured by MIDTRANS_BASE_URL and MIDTRANS_SERVER_KEY.
+//
+// An error is returned when MIDTRANS_SERVER_KEY is not set, since the notifications could not be verified. The
+// sandbox of Midtrans is only used by default in development, everywhere else MIDTRANS_BASE_URL must be set.
+func New() (domain.PaymentProvider, error) {
+	if viper.GetString("PAYMENT_PROVIDER") == "fake" {
+		if !config.IsDevelopment() {
+			return nil, errors.New("PAYMENT_PROVIDER=fake is only available in development")
+		}
+
+		server := StartFakeServer()
+
+		return NewMidtransProvider(server.URL(), server.ServerKey()), nil
+	}
+
+	serverKey := viper.GetString("MIDTRANS_SERVER_KEY")
+	if serverKey == "" {
+		return nil, errors.New("MIDTRANS_SERVER_KEY is not configured")
+	}
+
+	baseUrl := viper.GetString("MIDTRANS_BASE_URL")
+	if baseUrl == "" {
+		if !config.IsDevelopment() {
+			return nil, errors.New("MIDTRANS_BASE_URL is not configured")
+		}
+
+		baseUrl = "https://app.sandbox.midtrans.com"
+	}
+
+	return NewMidtransProvider(baseUrl, serverKey), nil
+}
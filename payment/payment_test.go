@@ -0,0 +1,22 @@
+package payment
+
+import (
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestNewRefusesTheFakeProviderOutsideDevelopment(t *testing.T) {
+	viper.Set("PAYMENT_PROVIDER", "fake")
+	viper.Set("MIDTRANS_SERVER_KEY", "server-key")
+	defer viper.Set("PAYMENT_PROVIDER", "")
+	defer viper.Set("MIDTRANS_SERVER_KEY", "")
+	defer viper.Set("APP_ENV", "")
+
+	for _, env := range []string{"production", "staging", ""} {
+		viper.Set("APP_ENV", env)
+
+		if _, err := New(); err == nil {
+			t.Fatalf("expected the fake provider to be refused with APP_ENV=%q", env)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package pdf writes single-page PDF documents made of text and lines, using the standard Helvetica fonts so that
+// no font has to be embedded.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size, in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Document struct {
+	content bytes.Buffer
+}
+
+func New() *Document {
+	return new(Document)
+}
+
+// Text writes a line of text whose baseline starts at (x, y), measured in points from the top left corner of
+// the page. Characters outside of Latin-1 are written as "?".
+func (doc *Document) Text(x float64, y float64, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+
+	_, _ = fmt.Fprintf(&doc.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y,
+		escape(text))
+}
+
+// TextRight writes a line of text ending at x, which is how amounts are aligned.
+func (doc *Document) TextRight(x float64, y float64, size float64, bold bool, text string) {
+	doc.Text(x-Width(text, size, bold), y, size, bold, text)
+}
+
+// Line draws a line from (x1, y1) to (x2, y2), measured in points from the top left corner of the page.
+func (doc *Document) Line(x1 float64, y1 float64, x2 float64, y2 float64) {
+	_, _ = fmt.Fprintf(&doc.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Bytes returns the document encoded as a PDF file.
+func (doc *Document) Bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", PageWidth, PageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", doc.content.Len(), doc.content.String()),
+	}
+
+	var file bytes.Buffer
+	file.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = file.Len()
+		_, _ = fmt.Fprintf(&file, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := file.Len()
+	_, _ = fmt.Fprintf(&file, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		_, _ = fmt.Fprintf(&file, "%010d 00000 n \n", offset)
+	}
+
+	_, _ = fmt.Fprintf(&file, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return file.Bytes()
+}
+
+// escape encodes the text as the content of a PDF string in WinAnsiEncoding.
+func escape(text string) string {
+	var builder strings.Builder
+
+	for _, char := range text {
+		switch {
+		case char == '(' || char == ')' || char == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(char)
+		case char < 0x20:
+			builder.WriteByte(' ')
+		case char < 0x80:
+			builder.WriteRune(char)
+		case char >= 0xa0 && char <= 0xff:
+			_, _ = fmt.Fprintf(&builder, "\\%03o", char)
+		default:
+			builder.WriteByte('?')
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// NormalizePhoneNumber converts a phone number to the E.164 format, e.g. +6281234567890.
+// Numbers without a country code are taken as Indonesian numbers.
+//
+// Spaces, dashes, dots and parentheses are ignored. A number starting with "0" (the Indonesian trunk prefix) or
+// "8" is a local number, and a number starting with "62" or "+" already carries its country code. Indonesian
+// numbers must be mobile numbers, as only those receive SMS and WhatsApp messages.
+//
+// Parameters:
+// - phoneNumber: the phone number as typed by the user.
+//
+// Returns:
+// - string: the phone number in the E.164 format.
+// - error: an error if the phone number is not valid.
+func NormalizePhoneNumber(phoneNumber string) (string, error) {
+	// Remove the separators commonly typed in phone numbers.
+	digits := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(phoneNumber)
+
+	// Add the Indonesian country code to the local numbers.
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		digits = digits[1:]
+	case strings.HasPrefix(digits, "0"):
+		digits = "62" + digits[1:]
+	case strings.HasPrefix(digits, "8"):
+		digits = "62" + digits
+	}
+
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", errors.New("phone number must only contain digits")
+	}
+
+	// E.164 numbers hold at most 15 digits and never start with 0.
+	if digits[0] == '0' || len(digits) < 8 || len(digits) > 15 {
+		return "", errors.New("phone number is not valid")
+	}
+
+	// Indonesian mobile numbers start with 8 and hold 9 to 12 digits after the country code.
+	// The trunk prefix is often kept after the country code (+62 0812...), it is dropped.
+	if national, found := strings.CutPrefix(digits, "62"); found {
+		national = strings.TrimPrefix(national, "0")
+
+		if !strings.HasPrefix(national, "8") || len(national) < 9 || len(national) > 12 {
+			return "", errors.New("phone number is not a valid Indonesian mobile number")
+		}
+
+		digits = "62" + national
+	}
+
+	return "+" + digits, nil
+}
@@ -0,0 +1,53 @@
+package utils
+
+import "testing"
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name        string
+		phoneNumber string
+		expected    string
+		valid       bool
+	}{
+		{name: "trunk prefix", phoneNumber: "081234567890", expected: "+6281234567890", valid: true},
+		{name: "without trunk prefix", phoneNumber: "81234567890", expected: "+6281234567890", valid: true},
+		{name: "country code", phoneNumber: "6281234567890", expected: "+6281234567890", valid: true},
+		{name: "country code and trunk prefix", phoneNumber: "+62 0812-3456-7890", expected: "+6281234567890", valid: true},
+		{name: "separators", phoneNumber: "(0812) 3456.7890", expected: "+6281234567890", valid: true},
+		{name: "shortest mobile number", phoneNumber: "0812345678", expected: "+62812345678", valid: true},
+		{name: "foreign number", phoneNumber: "+1 415 555 2671", expected: "+14155552671", valid: true},
+		{name: "landline", phoneNumber: "021-5551234", valid: false},
+		{name: "landline with country code", phoneNumber: "+62 21 5551234", valid: false},
+		{name: "too short", phoneNumber: "0812345", valid: false},
+		{name: "too short mobile number", phoneNumber: "081234567", valid: false},
+		{name: "too long", phoneNumber: "08123456789012", valid: false},
+		{name: "too long foreign number", phoneNumber: "+1234567890123456", valid: false},
+		{name: "non digits", phoneNumber: "0812-3456-abcd", valid: false},
+		{name: "plus sign inside", phoneNumber: "0812+34567890", valid: false},
+		{name: "empty", phoneNumber: "", valid: false},
+		{name: "only a plus sign", phoneNumber: "+", valid: false},
+		{name: "country code starting with 0", phoneNumber: "+0812345678", valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			phoneNumber, err := NormalizePhoneNumber(test.phoneNumber)
+
+			if !test.valid {
+				if err == nil {
+					t.Fatalf("expected %q to be rejected, got %s", test.phoneNumber, phoneNumber)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected %q to be accepted, got %v", test.phoneNumber, err)
+			}
+
+			if phoneNumber != test.expected {
+				t.Fatalf("expected %s, got %s", test.expected, phoneNumber)
+			}
+		})
+	}
+}
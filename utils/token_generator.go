@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// TokenGenerator generates a cryptographically secure random token.
+// The token is encoded using unpadded URL-safe base64 so it can be placed in URLs and JSON payloads as is.
+//
+// Parameters:
+// - size: the number of random bytes used to build the token.
+//
+// Returns:
+// - string: the generated token.
+// - error: an error if any occurred.
+func TokenGenerator(size int) (string, error) {
+	// Create a byte slice of the specified size and fill it with random bytes.
+	bytes := make([]byte, size)
+
+	_, err := rand.Read(bytes)
+	if err != nil {
+		// Return an empty string and the error if any occurred.
+		return "", err
+	}
+
+	// Encode the random bytes and return the token.
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// HashToken returns the hex encoded SHA-256 digest of a token.
+// It is meant for high entropy values such as refresh tokens, where a fast and deterministic
+// hash allows the stored value to be looked up directly.
+//
+// Parameters:
+// - value: the token to be hashed.
+//
+// Returns:
+// - string: the hex encoded digest of the token.
+func HashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// HmacToken returns the hex encoded HMAC-SHA256 of a value under the given key.
+// Unlike HashToken it is suited to low entropy values such as OTP codes, which cannot be brute forced
+// from their digest without knowing the key.
+//
+// Parameters:
+// - value: the value to be hashed.
+// - key: the secret key of the HMAC.
+//
+// Returns:
+// - string: the hex encoded HMAC of the value.
+func HmacToken(value string, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// totpPeriod is the number of seconds a TOTP code stays valid (RFC 6238 time step).
+	totpPeriod = 30
+
+	// totpDigits is the number of digits of a TOTP code.
+	totpDigits = 6
+)
+
+// TOTPSecretGenerator generates a random 160 bits TOTP secret.
+// The secret is returned base32 encoded without padding, as expected by authenticator apps.
+//
+// Returns:
+// - string: the generated secret.
+// - error: an error if any occurred.
+func TOTPSecretGenerator() (string, error) {
+	bytes := make([]byte, 20)
+
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bytes), nil
+}
+
+// TOTPURI builds the otpauth URI used by authenticator apps to register a TOTP secret, usually through a QR code.
+//
+// Parameters:
+// - issuer: the name of the application.
+// - account: the name of the account, usually its email.
+// - secret: the base32 encoded secret.
+//
+// Returns:
+// - string: the otpauth URI.
+func TOTPURI(issuer string, account string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TOTPStep returns the RFC 6238 time step containing the given time.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// TOTPCode computes the TOTP code of a time step, as defined by RFC 4226 and RFC 6238 (HMAC-SHA1, 6 digits).
+//
+// Parameters:
+// - secret: the base32 encoded secret.
+// - step: the time step.
+//
+// Returns:
+// - string: the TOTP code.
+// - error: an error if the secret is not valid base32.
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000), nil
+}
+
+// TOTPValidate checks a TOTP code at the given time.
+// To tolerate clock drift, the codes of the previous and the next time steps are accepted as well.
+//
+// Parameters:
+// - secret: the base32 encoded secret.
+// - code: the code to be checked.
+// - t: the time the code is checked at.
+//
+// Returns:
+// - int64: the time step matched by the code, so callers can refuse to accept the same step twice.
+// - bool: true if the code is valid, false otherwise.
+func TOTPValidate(secret string, code string, t time.Time) (int64, bool) {
+	current := TOTPStep(t)
+
+	for step := current - 1; step <= current+1; step++ {
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// RecoveryCodeGenerator generates a one-time recovery code such as "k7m2p-xq9rt".
+// Ambiguous characters (0, o, 1, l, i) are left out so the codes can be copied by hand.
+//
+// Returns:
+// - string: the generated recovery code.
+// - error: an error if any occurred.
+func RecoveryCodeGenerator() (string, error) {
+	const charset = "abcdefghjkmnpqrstuvwxyz23456789"
+
+	result := make([]byte, 10)
+	for i := range result {
+		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+
+		result[i] = charset[num.Int64()]
+	}
+
+	return string(result[:5]) + "-" + string(result[5:]), nil
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// UUIDGenerator generates a random (version 4) UUID.
+// It returns the UUID in its canonical textual representation and an error if any occurred.
+//
+// Returns:
+// - string: the generated UUID.
+// - error: an error if any occurred.
+func UUIDGenerator() (string, error) {
+	// Fill 16 bytes with random data.
+	bytes := make([]byte, 16)
+
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+
+	// Set the version (4) and variant (RFC 4122) bits.
+	bytes[6] = (bytes[6] & 0x0f) | 0x40
+	bytes[8] = (bytes[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16]), nil
+}